@@ -0,0 +1,525 @@
+// Command csvcheck compares the rows of two delimited files.
+//
+// Usage:
+//
+//	csvcheck [flags] left.csv right.csv
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+)
+
+// For holding the parsed command line flags.
+type config struct {
+	mode           string
+	method         string
+	useColumns     string
+	ignoreColumns  string
+	leftDelimiter  string
+	rightDelimiter string
+	leftEncoding   string
+	rightEncoding  string
+	format         string
+	width          int
+	pageSize       int
+	sheet          string
+	xlsxOut        string
+	progress       bool
+	columnMapping  string
+	header         string
+	duplicates     string
+	ragged         string
+	where          string
+	derived1       derivedColumns
+	derived2       derivedColumns
+	dates          string
+	timeZone       string
+	granularity    string
+	unordered      columnGroups
+	threshold      float64
+	keys           string
+	summary        bool
+	duplicateRows  bool
+}
+
+// For collecting derived columns from repeated name=expression flags.
+type derivedColumns []csvcheck.DerivedColumn
+
+func (d *derivedColumns) String() string {
+	columns := make([]string, len(*d))
+	for i, column := range *d {
+		columns[i] = column.Name + "=" + column.Expr
+	}
+	return strings.Join(columns, " ")
+}
+
+func (d *derivedColumns) Set(s string) error {
+	name, expr, found := strings.Cut(s, "=")
+	if !found || name == "" || expr == "" {
+		return fmt.Errorf("invalid derived column: %s", s)
+	}
+	*d = append(*d, csvcheck.DerivedColumn{Name: name, Expr: expr})
+	return nil
+}
+
+// Returns the comparison method with the given name.
+func parseMethod(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "match":
+		return csvcheck.MethodMatch, nil
+	case "direct":
+		return csvcheck.MethodDirect, nil
+	case "set":
+		return csvcheck.MethodSet, nil
+	case "fuzzy":
+		return csvcheck.MethodFuzzy, nil
+	}
+	return 0, fmt.Errorf("unsupported method: %s", s)
+}
+
+// Returns a list of column names from a comma separated flag value.
+func parseColumns(s string) []csvcheck.StringHashable {
+	if s == "" {
+		return nil
+	}
+	return csvcheck.GetRowFromRow(strings.Split(s, ","))
+}
+
+// Returns a mapping of left column names to right column names from a flag value
+// such as "cust_id=CustomerID,amount=Total".
+func parseColumnMapping(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	res := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		left, right, found := strings.Cut(pair, "=")
+		if !found || left == "" || right == "" {
+			return nil, fmt.Errorf("invalid column mapping: %s", pair)
+		}
+		res[left] = right
+	}
+	return res, nil
+}
+
+// Returns the read options for delimiter and encoding flag values. "auto" sniffs the
+// dialect or detects the encoding, respectively.
+func getReadOptions(delimiter, encoding string) (csvcheck.ReadOptions, error) {
+	var options csvcheck.ReadOptions
+	var err error
+	options.Encoding, err = csvcheck.ParseEncoding(encoding)
+	if err != nil {
+		return options, err
+	}
+	if delimiter == "auto" {
+		return options, nil
+	}
+
+	d, err := csvcheck.ParseDelimiter(delimiter)
+	if err != nil {
+		return options, err
+	}
+	dialect := csvcheck.DialectCsv
+	dialect.Delimiter = d
+	options.Dialect = &dialect
+	return options, nil
+}
+
+// Reads the file with the given name according to the flag values. Files with an .xlsx,
+// .parquet, .json or .jsonl extension are read as workbooks, parquet tables and JSON
+// records, respectively. Also returns whether the file likely has a header row.
+func readFile(name, delimiter, encoding string, cfg config) ([][]csvcheck.StringHashable, bool, error) {
+	var arr [][]csvcheck.StringHashable
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx":
+		arr, err = csvcheck.ReadXlsxFile(name, csvcheck.XlsxReadOptions{Sheet: cfg.sheet})
+		return arr, true, err
+	case ".parquet":
+		arr, err = csvcheck.ReadParquetFile(name, csvcheck.ParquetReadOptions{})
+		return arr, true, err
+	case ".json", ".jsonl", ".ndjson":
+		arr, err = csvcheck.ReadJsonFile(name, csvcheck.JsonReadOptions{})
+		return arr, true, err
+	}
+
+	options, err := getReadOptions(delimiter, encoding)
+	if err != nil {
+		return nil, false, err
+	}
+	arr, dialect, err := csvcheck.ReadCsvFile(name, options)
+	return arr, dialect.HasHeader, err
+}
+
+// For collecting column groups from repeated name=column,column flags.
+type columnGroups []csvcheck.ColumnGroup
+
+func (g *columnGroups) String() string {
+	groups := make([]string, len(*g))
+	for i, group := range *g {
+		columns := make([]string, len(group.Columns))
+		for j, column := range group.Columns {
+			columns[j] = column.StringHash()
+		}
+		groups[i] = group.Name + "=" + strings.Join(columns, ",")
+	}
+	return strings.Join(groups, " ")
+}
+
+func (g *columnGroups) Set(s string) error {
+	name, columns, found := strings.Cut(s, "=")
+	if !found || name == "" || columns == "" {
+		return fmt.Errorf("invalid column group: %s", s)
+	}
+	*g = append(*g, csvcheck.ColumnGroup{Name: name, Columns: parseColumns(columns)})
+	return nil
+}
+
+// Returns the date/time columns for the dates, time zone and granularity flag values.
+func parseDateColumns(dates, timeZone, granularity string) (map[string]csvcheck.DateColumn, error) {
+	if dates == "" {
+		return nil, nil
+	}
+
+	var column csvcheck.DateColumn
+	var err error
+	column.Granularity, err = csvcheck.ParseDateGranularity(granularity)
+	if err != nil {
+		return nil, err
+	}
+	column.TimeZone, err = time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]csvcheck.DateColumn)
+	for _, name := range strings.Split(dates, ",") {
+		res[name] = column
+	}
+	return res, nil
+}
+
+// Returns true iff the files should be compared without header rows according to the
+// header flag value. "auto" does so when neither file seems to have a header row.
+func parseNoHeader(s string, leftHasHeader, rightHasHeader bool) (bool, error) {
+	switch s {
+	case "auto":
+		return !leftHasHeader && !rightHasHeader, nil
+	case "yes":
+		return false, nil
+	case "no":
+		return true, nil
+	}
+	return false, fmt.Errorf("unsupported header value: %s", s)
+}
+
+// Returns the rows of a result without a header row preceded by a synthetic one, and
+// their indices preceded by a placeholder for it, so that it can be printed.
+func addPrintedHeader(arr [][]csvcheck.StringHashable, indices []int, numColumns int) ([][]csvcheck.StringHashable, []int) {
+	arr = append([][]csvcheck.StringHashable{csvcheck.GetSyntheticHeader(numColumns)}, arr...)
+	indices = append([]int{0}, indices...)
+	return arr, indices
+}
+
+// Prints one side of the comparison result.
+func printResult(title string, arr [][]csvcheck.StringHashable, indices []int, cfg config) error {
+	fmt.Printf("%s (%d rows)\n", title, len(arr)-1)
+
+	switch cfg.format {
+	case "csv":
+		return csvcheck.WriteCsvArray(os.Stdout, arr, csvcheck.DialectCsv)
+	case "table":
+		s, err := csvcheck.RenderTable(arr, csvcheck.TableOptions{
+			Borders:    true,
+			Wrap:       true,
+			MaxWidth:   cfg.width,
+			RowNumbers: indices,
+			PageSize:   cfg.pageSize,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Print(s)
+		return nil
+	}
+	return fmt.Errorf("unsupported format: %s", cfg.format)
+}
+
+// Prints the progress of a comparison to standard error on a single line.
+func printProgress(p csvcheck.Progress) {
+	switch p.Phase {
+	case csvcheck.ProgressPhaseHashing:
+		fmt.Fprintf(os.Stderr, "\r\033[Khashing rows %d/%d, about %s left", p.RowsHashed, p.TotalRows, p.Remaining.Round(time.Second))
+	case csvcheck.ProgressPhaseMatching:
+		fmt.Fprintf(os.Stderr, "\r\033[Kmatching %d rows", p.TotalRows)
+	case csvcheck.ProgressPhaseDone:
+		fmt.Fprintf(os.Stderr, "\r\033[Kcompared %d rows in %s\n", p.TotalRows, p.Elapsed.Round(time.Millisecond))
+	}
+}
+
+// Prints proposed pairings of differently named columns as a -map flag value.
+func printColumnPairings(left, right [][]csvcheck.StringHashable) {
+	pairings, err := csvcheck.SuggestColumnPairings(left, right, csvcheck.DefaultColumnMatchOptions)
+	if err != nil || len(pairings) == 0 {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "proposed pairings:")
+	pairs := make([]string, len(pairings))
+	for i, pairing := range pairings {
+		pairs[i] = pairing.Left.StringHash() + "=" + pairing.Right.StringHash()
+		fmt.Fprintf(os.Stderr, "  %s with %.0f%% confidence\n", pairs[i], 100*pairing.Confidence)
+	}
+	fmt.Fprintf(os.Stderr, "to pair them up, pass -map %q\n", strings.Join(pairs, ","))
+}
+
+// Prints the indices of the ragged rows of a file handled by options.RaggedRows to standard error.
+func printRaggedRows(title string, indices []int, options csvcheck.Options) {
+	if len(indices) == 0 {
+		return
+	}
+
+	action := "fixed"
+	if options.RaggedRows == csvcheck.RaggedRowsSkip {
+		action = "skipped"
+	}
+	rows := make([]string, len(indices))
+	for i, index := range indices {
+		rows[i] = strconv.Itoa(index)
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s %d ragged rows: %s\n", title, action, len(indices), strings.Join(rows, ", "))
+}
+
+// Prints the indices of the rows of a file with dates that could not be parsed to standard error.
+func printUnparsedDates(title string, indices []int) {
+	if len(indices) == 0 {
+		return
+	}
+
+	rows := make([]string, len(indices))
+	for i, index := range indices {
+		rows[i] = strconv.Itoa(index)
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d rows with unparsed dates compared as text: %s\n", title, len(indices), strings.Join(rows, ", "))
+}
+
+// Prints the rows paired by similarity to standard error.
+func printRowPairs(pairs []csvcheck.RowPair) {
+	if len(pairs) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%d rows paired by similarity:\n", len(pairs))
+	for _, pair := range pairs {
+		fmt.Fprintf(os.Stderr, "  left %d with right %d, %.0f%% similar\n", pair.Index1, pair.Index2, 100*pair.Score)
+	}
+}
+
+// Prints the groups of duplicate rows of the file to standard error.
+func printDuplicateRows(title string, arr [][]csvcheck.StringHashable, options csvcheck.Options, right bool) error {
+	find := csvcheck.FindDuplicateRows
+	if right {
+		find = csvcheck.FindDuplicateRightRows
+	}
+	groups, err := find(arr, options)
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	printed := make([]string, len(groups))
+	for i, group := range groups {
+		rows := make([]string, len(group))
+		for j, index := range group {
+			rows[j] = strconv.Itoa(index)
+		}
+		printed[i] = strings.Join(rows, ", ")
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d groups of duplicate rows: %s\n", title, len(groups), strings.Join(printed, "; "))
+	return nil
+}
+
+func run(ctx context.Context, cfg config, leftName, rightName string) error {
+	method, err := parseMethod(cfg.method)
+	if err != nil {
+		return err
+	}
+
+	columnMapping, err := parseColumnMapping(cfg.columnMapping)
+	if err != nil {
+		return err
+	}
+
+	left, leftHasHeader, err := readFile(leftName, cfg.leftDelimiter, cfg.leftEncoding, cfg)
+	if err != nil {
+		return err
+	}
+	right, rightHasHeader, err := readFile(rightName, cfg.rightDelimiter, cfg.rightEncoding, cfg)
+	if err != nil {
+		return err
+	}
+	noHeader, err := parseNoHeader(cfg.header, leftHasHeader, rightHasHeader)
+	if err != nil {
+		return err
+	}
+	duplicateColumns, err := csvcheck.ParseDuplicateColumns(cfg.duplicates)
+	if err != nil {
+		return err
+	}
+	raggedRows, err := csvcheck.ParseRaggedRows(cfg.ragged)
+	if err != nil {
+		return err
+	}
+	dateColumns, err := parseDateColumns(cfg.dates, cfg.timeZone, cfg.granularity)
+	if err != nil {
+		return err
+	}
+
+	options := csvcheck.Options{
+		Method:           method,
+		UseColumns:       parseColumns(cfg.useColumns),
+		IgnoreColumns:    parseColumns(cfg.ignoreColumns),
+		SortIndices:      true,
+		ColumnMapping:    columnMapping,
+		NoHeader:         noHeader,
+		DuplicateColumns: duplicateColumns,
+		RaggedRows:       raggedRows,
+		Where:            cfg.where,
+		DerivedColumns1:  cfg.derived1,
+		DerivedColumns2:  cfg.derived2,
+		DateColumns:      dateColumns,
+		UnorderedColumns: cfg.unordered,
+		Fuzzy:            csvcheck.RowMatchOptions{Threshold: cfg.threshold},
+		KeyColumns:       parseColumns(cfg.keys),
+	}
+
+	report, err := csvcheck.ReconcileHeaders(left, right, options)
+	if err != nil {
+		return err
+	}
+	if !report.Ok() {
+		fmt.Fprint(os.Stderr, report)
+		printColumnPairings(left, right)
+		return fmt.Errorf("the columns of %s and %s do not match", leftName, rightName)
+	}
+
+	var progress csvcheck.ProgressFunc
+	if cfg.progress {
+		progress = printProgress
+	}
+
+	var res csvcheck.RowsResult
+	switch cfg.mode {
+	case "common":
+		res, err = csvcheck.GetCommonRowsResult(ctx, left, right, options, progress)
+	case "different":
+		res, err = csvcheck.GetDifferentRowsResult(ctx, left, right, options, progress)
+	default:
+		err = fmt.Errorf("unsupported mode: %s", cfg.mode)
+	}
+	if err != nil {
+		return err
+	}
+	printRaggedRows(leftName, res.RaggedRows1, options)
+	printRaggedRows(rightName, res.RaggedRows2, options)
+	printUnparsedDates(leftName, res.UnparsedDates1)
+	printUnparsedDates(rightName, res.UnparsedDates2)
+	printRowPairs(res.Pairs)
+	if cfg.summary {
+		fmt.Fprint(os.Stderr, res.Summary)
+	}
+	if cfg.duplicateRows {
+		err = printDuplicateRows(leftName, left, options, false)
+		if err != nil {
+			return err
+		}
+		err = printDuplicateRows(rightName, right, options, true)
+		if err != nil {
+			return err
+		}
+	}
+	res1, res2, indices1, indices2 := res.Rows1, res.Rows2, res.Indices1, res.Indices2
+
+	if cfg.xlsxOut != "" {
+		err = csvcheck.WriteDiffXlsxFile(cfg.xlsxOut, left, right, options, csvcheck.XlsxWriteOptions{KeyColumns: options.KeyColumns})
+		if err != nil {
+			return err
+		}
+	}
+
+	if noHeader {
+		res1, indices1 = addPrintedHeader(res1, indices1, len(left[0])+len(cfg.derived1))
+		res2, indices2 = addPrintedHeader(res2, indices2, len(right[0])+len(cfg.derived2))
+	}
+
+	err = printResult(leftName, res1, indices1, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	return printResult(rightName, res2, indices2, cfg)
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.mode, "mode", "different", "rows to show: common or different")
+	flag.StringVar(&cfg.method, "method", "match", "comparison method: match, direct, set or fuzzy")
+	flag.StringVar(&cfg.useColumns, "use", "", "comma separated columns to compare")
+	flag.StringVar(&cfg.ignoreColumns, "ignore", "", "comma separated columns to leave out of the comparison")
+	flag.StringVar(&cfg.leftDelimiter, "d1", "auto", "delimiter of the left file, or auto to sniff it")
+	flag.StringVar(&cfg.rightDelimiter, "d2", "auto", "delimiter of the right file, or auto to sniff it")
+	flag.StringVar(&cfg.leftEncoding, "e1", "auto", "encoding of the left file, or auto to detect it")
+	flag.StringVar(&cfg.rightEncoding, "e2", "auto", "encoding of the right file, or auto to detect it")
+	flag.StringVar(&cfg.format, "format", "table", "output format: table or csv")
+	flag.IntVar(&cfg.width, "width", 0, "maximum table width, 0 for no limit")
+	flag.IntVar(&cfg.pageSize, "page", 0, "rows per table page, 0 for a single page")
+	flag.StringVar(&cfg.sheet, "sheet", "", "sheet to read from .xlsx files, the first sheet by default")
+	flag.StringVar(&cfg.xlsxOut, "xlsx", "", "also write the comparison to this .xlsx file")
+	flag.StringVar(&cfg.columnMapping, "map", "", "comma separated left=right pairs of columns named differently in the right file")
+	flag.StringVar(&cfg.header, "header", "auto", "whether the files have a header row: yes, no, or auto to sniff it")
+	flag.StringVar(&cfg.duplicates, "dupes", "fail", "handling of duplicate column names: fail, suffix or position")
+	flag.StringVar(&cfg.ragged, "ragged", "fail", "handling of rows with a different number of cells: fail, pad, truncate, fit or skip")
+	flag.StringVar(&cfg.where, "where", "", "only compare rows matching this filter, such as 'status != \"cancelled\" && amount > 0'")
+	flag.Var(&cfg.derived1, "derive1", "add a column computed from the left file, such as 'full_name=concat(first, \" \", last)'; repeatable")
+	flag.Var(&cfg.derived2, "derive2", "add a column computed from the right file, such as 'amount=round(cents / 100, 2)'; repeatable")
+	flag.StringVar(&cfg.dates, "dates", "", "comma separated date/time columns to compare as instants rather than text")
+	flag.StringVar(&cfg.timeZone, "tz", "UTC", "time zone of date/time values without an offset, and of the comparison")
+	flag.StringVar(&cfg.granularity, "granularity", "ms", "granularity of date/time comparisons: ms, second, minute or day")
+	flag.Var(&cfg.unordered, "unordered", "compare a group of columns regardless of the order of their values, such as 'tags=tag1,tag2,tag3'; repeatable")
+	flag.Float64Var(&cfg.threshold, "threshold", csvcheck.DefaultRowMatchThreshold, "minimum similarity of rows paired by the fuzzy method, between 0 and 1")
+	flag.StringVar(&cfg.keys, "keys", "", "comma separated columns identifying the same record in both files, for counting and highlighting modified rows")
+	flag.BoolVar(&cfg.summary, "summary", false, "show statistics of the comparison on standard error")
+	flag.BoolVar(&cfg.duplicateRows, "duprows", false, "list the groups of duplicate rows of each file on standard error, by -keys if given")
+	flag.BoolVar(&cfg.progress, "progress", false, "show the progress of the comparison on standard error")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: csvcheck [flags] left right\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, cfg, flag.Arg(0), flag.Arg(1))
+	stop()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "csvcheck:", err)
+		os.Exit(1)
+	}
+}
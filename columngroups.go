@@ -0,0 +1,84 @@
+package csvcheck
+
+import (
+	"fmt"
+	"sort"
+)
+
+// For holding a named group of columns whose values are compared as a multiset, such as
+// tag1, tag2 and tag3 holding an unordered list of tags. Rows that only differ in the
+// order of the values within the group are considered equal.
+type ColumnGroup struct {
+	Name    string
+	Columns []StringHashable // Columns can also be given by ColumnIndex.
+}
+
+// Returns the indices of the columns of each group in the header.
+func getColumnGroupIndices(header []StringHashable, groups []ColumnGroup) ([][]int, error) {
+	res := make([][]int, len(groups))
+	used := make(map[int]string)
+	for g, group := range groups {
+		columns, err := resolveColumnIndices(header, group.Columns)
+		if err != nil {
+			return nil, fmt.Errorf("column group %s: %w", group.Name, err)
+		}
+		indices, err := getColumnIndices(header, columns)
+		if err != nil {
+			return nil, fmt.Errorf("column group %s: %w", group.Name, err)
+		}
+		for _, index := range indices {
+			if other, exists := used[index]; exists {
+				return nil, fmt.Errorf("column %s is in column groups %s and %s", header[index].StringHash(), other, group.Name)
+			}
+			used[index] = group.Name
+		}
+		res[g] = indices
+	}
+	return res, nil
+}
+
+// Returns a shallow copy of the array with the values of each group of columns sorted
+// in the order the columns are given in, without checking the array. Returns the
+// array itself if there are no groups.
+func sortColumnGroups(arr [][]StringHashable, groups []ColumnGroup) ([][]StringHashable, error) {
+	if len(groups) == 0 {
+		return arr, nil
+	}
+	groupIndices, err := getColumnGroupIndices(arr[0], groups)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([][]StringHashable, len(arr))
+	res[0] = arr[0]
+	for i := 1; i < len(arr); i++ {
+		row := make([]StringHashable, len(arr[i]))
+		copy(row, arr[i])
+		for _, indices := range groupIndices {
+			values := make([]StringHashable, len(indices))
+			for j, index := range indices {
+				values[j] = row[index]
+			}
+			sort.SliceStable(values, func(a, b int) bool {
+				return values[a].StringHash() < values[b].StringHash()
+			})
+			for j, index := range indices {
+				row[index] = values[j]
+			}
+		}
+		res[i] = row
+	}
+	return res, nil
+}
+
+// Returns a copy of the array with the values of each group of columns sorted, so that
+// rows holding the same values in a different order within the groups become equal.
+// The sorted values are placed in the order the columns of each group are given in.
+// Returns a ColumnNotFoundError for columns missing from the header.
+func SortColumnGroups(arr [][]StringHashable, groups []ColumnGroup) ([][]StringHashable, error) {
+	err := CheckForProperCsvArray(arr)
+	if err != nil {
+		return nil, err
+	}
+	return sortColumnGroups(arr, groups)
+}
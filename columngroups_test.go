@@ -0,0 +1,73 @@
+package csvcheck_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTagsCsvArray() [][]csvcheck.StringHashable {
+	return csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "tag1", "tag2", "tag3"},
+		{"1", "red", "blue", "green"},
+		{"2", "small", "", "large"},
+		{"3", "a", "a", "b"},
+	})
+}
+
+func TestSortColumnGroups(t *testing.T) {
+	arr := getTagsCsvArray()
+	groups := []csvcheck.ColumnGroup{{Name: "tags", Columns: csvcheck.GetRowFromRow([]string{"tag3", "tag1", "tag2"})}}
+
+	res, err := csvcheck.SortColumnGroups(arr, groups)
+
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "tag1", "tag2", "tag3"},
+		{"1", "green", "red", "blue"},
+		{"2", "large", "small", ""},
+		{"3", "a", "b", "a"},
+	}), res)
+	assert.Equal(t, "red", arr[1][1].StringHash())
+}
+
+func TestSortColumnGroupsInvalid(t *testing.T) {
+	arr := getTagsCsvArray()
+
+	_, err := csvcheck.SortColumnGroups(arr, []csvcheck.ColumnGroup{{Name: "tags", Columns: csvcheck.GetRowFromRow([]string{"tag1", "tag4"})}})
+	var notFound *csvcheck.ColumnNotFoundError
+	assert.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "tag4", notFound.Name)
+
+	_, err = csvcheck.SortColumnGroups(arr, []csvcheck.ColumnGroup{
+		{Name: "first", Columns: csvcheck.GetRowFromRow([]string{"tag1", "tag2"})},
+		{Name: "second", Columns: csvcheck.GetRowFromRow([]string{"tag2", "tag3"})},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestGetDifferentRowsUnorderedColumns(t *testing.T) {
+	arr1 := getTagsCsvArray()
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"tag3", "id", "tag2", "tag1"},
+		{"red", "1", "green", "blue"},
+		{"small", "2", "large", "x"},
+		{"a", "3", "b", "a"},
+	})
+	options := csvcheck.Options{SortIndices: true}
+
+	_, _, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, indices1)
+	assert.Equal(t, []int{0, 1, 2, 3}, indices2)
+
+	options.UnorderedColumns = []csvcheck.ColumnGroup{{Name: "tags", Columns: []csvcheck.StringHashable{csvcheck.ColumnIndex(1), csvcheck.BasicStringHashable("tag2"), csvcheck.BasicStringHashable("tag3")}}}
+	res1, _, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2}, indices1)
+	assert.Equal(t, []int{0, 2}, indices2)
+	assert.Equal(t, arr1[2], res1[1])
+}
@@ -0,0 +1,177 @@
+package csvcheck
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Supported compression formats.
+const (
+	CompressionAuto = iota
+	CompressionNone
+	CompressionGzip
+	CompressionZstd
+	CompressionBzip2
+	CompressionXz
+)
+
+// Magic bytes at the start of compressed streams.
+var (
+	magicGzip  = []byte{0x1F, 0x8B}
+	magicZstd  = []byte{0x28, 0xB5, 0x2F, 0xFD}
+	magicBzip2 = []byte("BZh")
+	magicXz    = []byte{0xFD, '7', 'z', 'X', 'Z', 0x00}
+)
+
+// Returns the compression format with the given name.
+func ParseCompression(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return CompressionAuto, nil
+	case "none":
+		return CompressionNone, nil
+	case "gzip", "gz":
+		return CompressionGzip, nil
+	case "zstd", "zst":
+		return CompressionZstd, nil
+	case "bzip2", "bz2":
+		return CompressionBzip2, nil
+	case "xz":
+		return CompressionXz, nil
+	}
+	return 0, &InvalidOptionError{Option: "compression", Value: s}
+}
+
+// Returns the compression format implied by the extension of a file name.
+func GetCompressionFromName(name string) int {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".gz", ".gzip":
+		return CompressionGzip
+	case ".zst", ".zstd":
+		return CompressionZstd
+	case ".bz2":
+		return CompressionBzip2
+	case ".xz":
+		return CompressionXz
+	}
+	return CompressionNone
+}
+
+// Returns the compression format of a stream from the magic bytes at its start.
+func DetectCompression(header []byte) int {
+	switch {
+	case bytes.HasPrefix(header, magicGzip):
+		return CompressionGzip
+	case bytes.HasPrefix(header, magicZstd):
+		return CompressionZstd
+	case bytes.HasPrefix(header, magicBzip2):
+		return CompressionBzip2
+	case bytes.HasPrefix(header, magicXz):
+		return CompressionXz
+	}
+	return CompressionNone
+}
+
+// Returns a reader that decompresses r, along with the compression format used,
+// which is detected from the magic bytes at the start of r for CompressionAuto.
+func NewDecompressingReader(r io.Reader, compression int) (io.ReadCloser, int, error) {
+	return newDecompressingReader(r, compression, CompressionNone)
+}
+
+// Returns a reader that decompresses the file r with the given name like
+// NewDecompressingReader. For CompressionAuto, the compression format given by the
+// extension of the name is used when the magic bytes show none, so that a corrupted
+// compressed file is reported as such instead of being read as text.
+func newDecompressingFileReader(r io.Reader, name string, compression int) (io.ReadCloser, int, error) {
+	return newDecompressingReader(r, compression, GetCompressionFromName(name))
+}
+
+// Returns a reader that decompresses r, using fallback for CompressionAuto when the
+// magic bytes at the start of r show no compression.
+func newDecompressingReader(r io.Reader, compression, fallback int) (io.ReadCloser, int, error) {
+	br := bufio.NewReader(r)
+	if compression == CompressionAuto {
+		header, err := br.Peek(len(magicXz))
+		if err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		compression = DetectCompression(header)
+		if compression == CompressionNone {
+			compression = fallback
+		}
+	}
+
+	switch compression {
+	case CompressionNone:
+		return io.NopCloser(br), compression, nil
+	case CompressionGzip:
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, 0, err
+		}
+		return zr, compression, nil
+	case CompressionZstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, 0, err
+		}
+		return zr.IOReadCloser(), compression, nil
+	case CompressionBzip2:
+		return io.NopCloser(bzip2.NewReader(br)), compression, nil
+	case CompressionXz:
+		zr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, 0, err
+		}
+		return io.NopCloser(zr), compression, nil
+	}
+	return nil, 0, &InvalidOptionError{Option: "compression", Value: fmt.Sprint(compression)}
+}
+
+// A WriteCloser that does nothing when closed.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// Checks if writing the compression format is supported.
+func checkWritableCompression(compression int) error {
+	switch compression {
+	case CompressionNone, CompressionGzip, CompressionZstd, CompressionXz:
+		return nil
+	case CompressionBzip2:
+		return &InvalidOptionError{Option: "compression", Value: "bzip2", Reason: "writing bzip2 is not supported"}
+	}
+	return &InvalidOptionError{Option: "compression", Value: fmt.Sprint(compression)}
+}
+
+// Returns a writer that compresses everything written to it into w. Closing it
+// flushes the compressed stream but does not close w. Writing bzip2 is not supported.
+func NewCompressingWriter(w io.Writer, compression int) (io.WriteCloser, error) {
+	err := checkWritableCompression(compression)
+	if err != nil {
+		return nil, err
+	}
+
+	switch compression {
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	}
+	return xz.NewWriter(w)
+}
@@ -0,0 +1,121 @@
+package csvcheck_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCompressionFromName(t *testing.T) {
+	assert.Equal(t, csvcheck.CompressionGzip, csvcheck.GetCompressionFromName("a.csv.gz"))
+	assert.Equal(t, csvcheck.CompressionZstd, csvcheck.GetCompressionFromName("a.csv.ZST"))
+	assert.Equal(t, csvcheck.CompressionBzip2, csvcheck.GetCompressionFromName("a.csv.bz2"))
+	assert.Equal(t, csvcheck.CompressionXz, csvcheck.GetCompressionFromName("a.csv.xz"))
+	assert.Equal(t, csvcheck.CompressionNone, csvcheck.GetCompressionFromName("a.csv"))
+}
+
+func TestDetectCompression(t *testing.T) {
+	assert.Equal(t, csvcheck.CompressionGzip, csvcheck.DetectCompression([]byte{0x1F, 0x8B, 0x08}))
+	assert.Equal(t, csvcheck.CompressionZstd, csvcheck.DetectCompression([]byte{0x28, 0xB5, 0x2F, 0xFD}))
+	assert.Equal(t, csvcheck.CompressionBzip2, csvcheck.DetectCompression([]byte("BZh91AY")))
+	assert.Equal(t, csvcheck.CompressionXz, csvcheck.DetectCompression([]byte{0xFD, '7', 'z', 'X', 'Z', 0x00}))
+	assert.Equal(t, csvcheck.CompressionNone, csvcheck.DetectCompression([]byte("a,b\n")))
+	assert.Equal(t, csvcheck.CompressionNone, csvcheck.DetectCompression([]byte{}))
+}
+
+func TestCompressingWriterDecompressingReaderRoundTrip(t *testing.T) {
+	data := []byte("a,b,c\n1,2,3\n4,5,6\n")
+	for _, compression := range []int{
+		csvcheck.CompressionNone,
+		csvcheck.CompressionGzip,
+		csvcheck.CompressionZstd,
+		csvcheck.CompressionXz,
+	} {
+		var buf bytes.Buffer
+		w, err := csvcheck.NewCompressingWriter(&buf, compression)
+		assert.Nil(t, err)
+		_, err = w.Write(data)
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+
+		r, detected, err := csvcheck.NewDecompressingReader(&buf, csvcheck.CompressionAuto)
+		assert.Nil(t, err)
+		assert.Equal(t, compression, detected)
+		res, err := io.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Nil(t, r.Close())
+		assert.Equal(t, data, res)
+	}
+}
+
+func TestNewCompressingWriterBzip2Unsupported(t *testing.T) {
+	_, err := csvcheck.NewCompressingWriter(io.Discard, csvcheck.CompressionBzip2)
+
+	assert.NotNil(t, err)
+}
+
+func TestWriteCsvFileBzip2LeavesNoFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "snapshot.csv.bz2")
+
+	err := csvcheck.WriteCsvFile(name, getCsvArray2(), csvcheck.DialectCsv)
+	assert.NotNil(t, err)
+	_, err = os.Stat(name)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReadCsvFileCompressionFromExtension(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "corrupted.csv.gz")
+	err := os.WriteFile(name, []byte("a,b\n1,2\n"), 0o644)
+	assert.Nil(t, err)
+
+	_, _, err = csvcheck.ReadCsvFile(name, csvcheck.ReadOptions{})
+	assert.NotNil(t, err)
+
+	arr, _, err := csvcheck.ReadCsvFile(name, csvcheck.ReadOptions{Compression: csvcheck.CompressionNone})
+	assert.Nil(t, err)
+	assert.Len(t, arr, 2)
+}
+
+func TestReadCsvFileCompressedAgainstUncompressed(t *testing.T) {
+	dir := t.TempDir()
+	arr := getCsvArray2()
+	for _, name := range []string{"plain.csv", "snapshot.csv.gz", "snapshot.csv.zst", "snapshot.csv.xz"} {
+		err := csvcheck.WriteCsvFile(filepath.Join(dir, name), arr, csvcheck.DialectCsv)
+		assert.Nil(t, err)
+	}
+
+	// Compression is detected from the content, not the name.
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte("a;b;c\n10;10;10\n4;5;6\n"))
+	assert.Nil(t, zw.Close())
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "misnamed.csv"), buf.Bytes(), 0o644))
+
+	plain, _, err := csvcheck.ReadCsvFile(filepath.Join(dir, "plain.csv"), csvcheck.ReadOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, arr, plain)
+
+	for _, name := range []string{"snapshot.csv.gz", "snapshot.csv.zst", "snapshot.csv.xz"} {
+		compressed, _, err := csvcheck.ReadCsvFile(filepath.Join(dir, name), csvcheck.ReadOptions{})
+		assert.Nil(t, err)
+
+		_, _, indices1, indices2, err := csvcheck.GetDifferentRows(plain, compressed, csvcheck.Options{})
+		assert.Nil(t, err)
+		assert.Equal(t, []int{0}, indices1)
+		assert.Equal(t, []int{0}, indices2)
+	}
+
+	misnamed, dialect, err := csvcheck.ReadCsvFile(filepath.Join(dir, "misnamed.csv"), csvcheck.ReadOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, ';', dialect.Delimiter)
+	_, _, indices1, _, err := csvcheck.GetCommonRows(plain, misnamed, csvcheck.Options{SortIndices: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2}, indices1)
+}
@@ -1,7 +1,9 @@
 package csvcheck
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -13,6 +15,7 @@ const (
 	MethodMatch = iota
 	MethodDirect
 	MethodSet
+	MethodFuzzy
 )
 
 // For marking truncated pretty formatted strings.
@@ -38,20 +41,64 @@ func getStringKey(s StringHashable) uint64 {
 
 // For holding supported options.
 type Options struct {
-	Method        int
-	UseColumns    []StringHashable
-	IgnoreColumns []StringHashable
-	SortIndices   bool
+	Method           int
+	UseColumns       []StringHashable
+	IgnoreColumns    []StringHashable
+	SortIndices      bool
+	ColumnMapping    map[string]string     // Maps left column names to the names of the same columns on the right.
+	NoHeader         bool                  // The arrays have no header row. Their columns are named col1 to colN.
+	DuplicateColumns int                   // How duplicate column names are handled, see DisambiguateHeader.
+	RaggedRows       int                   // How rows with a different number of cells than the first row are handled.
+	Where            string                // Only rows matching this filter expression are compared, see RowFilter.
+	DerivedColumns1  []DerivedColumn       // Columns computed from the other columns of the first array before comparing.
+	DerivedColumns2  []DerivedColumn       // Columns computed from the other columns of the second array before comparing.
+	DateColumns      map[string]DateColumn // Date/time columns by name, normalized before comparing. Values that cannot be parsed are compared as they are.
+	UnorderedColumns []ColumnGroup         // Groups of columns whose values are compared regardless of their order.
+	Fuzzy            RowMatchOptions       // How rows are paired by similarity with MethodFuzzy.
+	KeyColumns       []StringHashable      // Different rows with equal values in these compared columns are counted as modified in the Summary.
 }
 
 // Checks if the options are valid.
 func (o *Options) CheckAttributes() error {
-	if o.Method != MethodMatch && o.Method != MethodDirect && o.Method != MethodSet {
-		return fmt.Errorf("unsupported method: %d", o.Method)
+	if o.Method != MethodMatch && o.Method != MethodDirect && o.Method != MethodSet && o.Method != MethodFuzzy {
+		return &UnsupportedMethodError{Method: o.Method}
 	}
 
 	if o.UseColumns != nil && o.IgnoreColumns != nil {
-		return fmt.Errorf("cannot use both UseColumns and IgnoreColumns together")
+		return ErrConflictingColumns
+	}
+
+	if o.DuplicateColumns != DuplicateColumnsFail && o.DuplicateColumns != DuplicateColumnsSuffix && o.DuplicateColumns != DuplicateColumnsPosition {
+		return &InvalidOptionError{Option: "duplicate columns mode", Value: fmt.Sprint(o.DuplicateColumns)}
+	}
+
+	err := checkRaggedRows(o.RaggedRows)
+	if err != nil {
+		return err
+	}
+
+	err = checkColumnMapping(o.ColumnMapping)
+	if err != nil {
+		return err
+	}
+
+	if o.Where != "" {
+		_, err = ParseRowFilter(o.Where)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = o.Fuzzy.CheckAttributes()
+	if err != nil {
+		return err
+	}
+
+	for _, column := range o.DateColumns {
+		err = column.CheckAttributes()
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -118,11 +165,10 @@ func getRowKey(row []StringHashable) rowKey {
 	return res
 }
 
-// Returns a mapping of rows to sorted lists of their indices in the input array.
-func getRowsMapping(arr [][]StringHashable) map[rowKey][]int {
+// Returns a mapping of row keys to sorted lists of their indices in the input array.
+func getRowsMapping(keys []rowKey) map[rowKey][]int {
 	mapping := make(map[rowKey][]int)
-	for i, row := range arr {
-		key := getRowKey(row)
+	for i, key := range keys {
 		mapping[key] = append(mapping[key], i)
 	}
 	return mapping
@@ -130,10 +176,7 @@ func getRowsMapping(arr [][]StringHashable) map[rowKey][]int {
 
 // Returns the indices of rows common to both arrays
 // using the match method.
-func getCommonIndicesMatch(arr1, arr2 [][]StringHashable) ([]int, []int) {
-	rowsMapping1 := getRowsMapping(arr1)
-	rowsMapping2 := getRowsMapping(arr2)
-
+func getCommonIndicesMatch(rowsMapping1, rowsMapping2 map[rowKey][]int) ([]int, []int) {
 	commonIndices1 := []int{}
 	commonIndices2 := []int{}
 	for key, indices1 := range rowsMapping1 {
@@ -148,11 +191,11 @@ func getCommonIndicesMatch(arr1, arr2 [][]StringHashable) ([]int, []int) {
 
 // Returns the indices of rows common to both arrays
 // using the direct method.
-func getCommonIndicesDirect(arr1, arr2 [][]StringHashable) ([]int, []int) {
+func getCommonIndicesDirect(keys1, keys2 []rowKey) ([]int, []int) {
 	commonIndices1 := []int{}
 	commonIndices2 := []int{}
-	for i := 0; i < len(arr1) && i < len(arr2); i++ {
-		if getRowKey(arr1[i]) == getRowKey(arr2[i]) {
+	for i := 0; i < len(keys1) && i < len(keys2); i++ {
+		if keys1[i] == keys2[i] {
 			commonIndices1 = append(commonIndices1, i)
 			commonIndices2 = append(commonIndices2, i)
 		}
@@ -162,10 +205,7 @@ func getCommonIndicesDirect(arr1, arr2 [][]StringHashable) ([]int, []int) {
 
 // Returns the indices of rows common to both arrays
 // using the set method.
-func getCommonIndicesSet(arr1, arr2 [][]StringHashable) ([]int, []int) {
-	rowsMapping1 := getRowsMapping(arr1)
-	rowsMapping2 := getRowsMapping(arr2)
-
+func getCommonIndicesSet(rowsMapping1, rowsMapping2 map[rowKey][]int) ([]int, []int) {
 	commonIndices1 := []int{}
 	commonIndices2 := []int{}
 	for key, indices1 := range rowsMapping1 {
@@ -177,37 +217,97 @@ func getCommonIndicesSet(arr1, arr2 [][]StringHashable) ([]int, []int) {
 	return commonIndices1, commonIndices2
 }
 
-// Returns the indices of rows common to both arrays
-// based on the method given.
-func GetCommonIndices(arr1, arr2 [][]StringHashable, method int, sortIndices bool) ([]int, []int, error) {
-	var indices1 []int
-	var indices2 []int
+// For holding the result of comparing the rows of two arrays.
+type rowsComparison struct {
+	keys1      []rowKey
+	keys2      []rowKey
+	mapping1   map[rowKey][]int // The indices of the rows of the first array by key, see getRowsMapping.
+	mapping2   map[rowKey][]int // The indices of the rows of the second array by key.
+	common1    []int
+	common2    []int
+	different1 []int
+	different2 []int
+	pairs      []RowPair // The rows paired by MethodFuzzy.
+}
+
+// Compares the rows of the arrays with the given method, finding both the common and
+// the different rows. The compared columns are given by header, which the arrays do
+// not include, and are only used by MethodFuzzy.
+func compareRows(ctx context.Context, arr1, arr2 [][]StringHashable, method int, header []StringHashable, fuzzy RowMatchOptions, progress ProgressFunc) (rowsComparison, error) {
+	t := newProgressTracker(ctx, progress, len(arr1)+len(arr2))
+	keys1, err := t.getRowKeys(arr1)
+	if err != nil {
+		return rowsComparison{}, err
+	}
+	keys2, err := t.getRowKeys(arr2)
+	if err != nil {
+		return rowsComparison{}, err
+	}
+	err = t.setPhase(ProgressPhaseMatching)
+	if err != nil {
+		return rowsComparison{}, err
+	}
 
+	res := rowsComparison{
+		keys1:    keys1,
+		keys2:    keys2,
+		mapping1: getRowsMapping(keys1),
+		mapping2: getRowsMapping(keys2),
+	}
 	switch method {
 	case MethodMatch:
-		indices1, indices2 = getCommonIndicesMatch(arr1, arr2)
+		res.common1, res.common2 = getCommonIndicesMatch(res.mapping1, res.mapping2)
+		res.different1, res.different2 = getDifferentIndicesMatch(res.mapping1, res.mapping2)
 	case MethodDirect:
-		indices1, indices2 = getCommonIndicesDirect(arr1, arr2)
+		res.common1, res.common2 = getCommonIndicesDirect(keys1, keys2)
+		res.different1, res.different2 = getDifferentIndicesDirect(keys1, keys2)
 	case MethodSet:
-		indices1, indices2 = getCommonIndicesSet(arr1, arr2)
-	default:
-		return nil, nil, fmt.Errorf("unsupported method: %d", method)
+		res.common1, res.common2 = getCommonIndicesSet(res.mapping1, res.mapping2)
+		res.different1, res.different2 = getDifferentIndicesSet(res.mapping1, res.mapping2)
+	case MethodFuzzy:
+		err = res.matchFuzzy(ctx, arr1, arr2, header, fuzzy)
+		if err != nil {
+			return rowsComparison{}, err
+		}
+	}
+
+	err = t.setPhase(ProgressPhaseDone)
+	if err != nil {
+		return rowsComparison{}, err
+	}
+	return res, nil
+}
+
+// Returns the indices of rows common to both arrays
+// based on the method given.
+func GetCommonIndices(arr1, arr2 [][]StringHashable, method int, sortIndices bool) ([]int, []int, error) {
+	return GetCommonIndicesContext(context.Background(), arr1, arr2, method, sortIndices, nil)
+}
+
+// Returns the indices of rows common to both arrays
+// based on the method given. Stops with the error of ctx once it is done
+// and reports the progress of the comparison to progress, which may be nil.
+func GetCommonIndicesContext(ctx context.Context, arr1, arr2 [][]StringHashable, method int, sortIndices bool, progress ProgressFunc) ([]int, []int, error) {
+	if method != MethodMatch && method != MethodDirect && method != MethodSet {
+		return nil, nil, &UnsupportedMethodError{Method: method}
 	}
 
+	res, err := compareRows(ctx, arr1, arr2, method, nil, RowMatchOptions{}, progress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	indices1, indices2 := res.common1, res.common2
 	if sortIndices {
 		sort.Ints(indices1)
 		sort.Ints(indices2)
 	}
-
 	return indices1, indices2, nil
 }
 
 // Returns the indices of rows that are different between the two arrays
 // using the match method.
-func getDifferentIndicesMatch(arr1, arr2 [][]StringHashable) ([]int, []int) {
-	rowsMapping1 := getRowsMapping(arr1)
-	rowsMapping2 := getRowsMapping(arr2)
-
+func getDifferentIndicesMatch(rowsMapping1, rowsMapping2 map[rowKey][]int) ([]int, []int) {
 	differentIndices1 := []int{}
 	differentIndices2 := []int{}
 	for key, indices1 := range rowsMapping1 {
@@ -231,22 +331,22 @@ func getDifferentIndicesMatch(arr1, arr2 [][]StringHashable) ([]int, []int) {
 
 // Returns the indices of rows that are different between the two arrays
 // using the direct method.
-func getDifferentIndicesDirect(arr1, arr2 [][]StringHashable) ([]int, []int) {
+func getDifferentIndicesDirect(keys1, keys2 []rowKey) ([]int, []int) {
 	differentIndices1 := []int{}
 	differentIndices2 := []int{}
 
 	i := 0
-	for ; i < len(arr1) && i < len(arr2); i++ {
-		if getRowKey(arr1[i]) != getRowKey(arr2[i]) {
+	for ; i < len(keys1) && i < len(keys2); i++ {
+		if keys1[i] != keys2[i] {
 			differentIndices1 = append(differentIndices1, i)
 			differentIndices2 = append(differentIndices2, i)
 		}
 	}
 
-	for ; i < len(arr1); i++ {
+	for ; i < len(keys1); i++ {
 		differentIndices1 = append(differentIndices1, i)
 	}
-	for ; i < len(arr2); i++ {
+	for ; i < len(keys2); i++ {
 		differentIndices2 = append(differentIndices2, i)
 	}
 
@@ -255,10 +355,7 @@ func getDifferentIndicesDirect(arr1, arr2 [][]StringHashable) ([]int, []int) {
 
 // Returns the indices of rows that are different between the two arrays
 // using the set method.
-func getDifferentIndicesSet(arr1, arr2 [][]StringHashable) ([]int, []int) {
-	rowsMapping1 := getRowsMapping(arr1)
-	rowsMapping2 := getRowsMapping(arr2)
-
+func getDifferentIndicesSet(rowsMapping1, rowsMapping2 map[rowKey][]int) ([]int, []int) {
 	differentIndices1 := []int{}
 	differentIndices2 := []int{}
 	for key, indices1 := range rowsMapping1 {
@@ -278,25 +375,27 @@ func getDifferentIndicesSet(arr1, arr2 [][]StringHashable) ([]int, []int) {
 // Returns the indices of rows that are different between the two arrays
 // based on the method given.
 func GetDifferentIndices(arr1, arr2 [][]StringHashable, method int, sortIndices bool) ([]int, []int, error) {
-	var indices1 []int
-	var indices2 []int
+	return GetDifferentIndicesContext(context.Background(), arr1, arr2, method, sortIndices, nil)
+}
 
-	switch method {
-	case MethodMatch:
-		indices1, indices2 = getDifferentIndicesMatch(arr1, arr2)
-	case MethodDirect:
-		indices1, indices2 = getDifferentIndicesDirect(arr1, arr2)
-	case MethodSet:
-		indices1, indices2 = getDifferentIndicesSet(arr1, arr2)
-	default:
-		return nil, nil, fmt.Errorf("unsupported method: %d", method)
+// Returns the indices of rows that are different between the two arrays
+// based on the method given. Stops with the error of ctx once it is done
+// and reports the progress of the comparison to progress, which may be nil.
+func GetDifferentIndicesContext(ctx context.Context, arr1, arr2 [][]StringHashable, method int, sortIndices bool, progress ProgressFunc) ([]int, []int, error) {
+	if method != MethodMatch && method != MethodDirect && method != MethodSet {
+		return nil, nil, &UnsupportedMethodError{Method: method}
+	}
+
+	res, err := compareRows(ctx, arr1, arr2, method, nil, RowMatchOptions{}, progress)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	indices1, indices2 := res.different1, res.different2
 	if sortIndices {
 		sort.Ints(indices1)
 		sort.Ints(indices2)
 	}
-
 	return indices1, indices2, nil
 }
 
@@ -304,7 +403,7 @@ func GetDifferentIndices(arr1, arr2 [][]StringHashable, method int, sortIndices
 // and all rows have the same number elements.
 func CheckForProperCsvArray(arr [][]StringHashable) error {
 	if len(arr) == 0 {
-		return fmt.Errorf("empty array")
+		return ErrEmptyArray
 	}
 
 	marker := make(map[uint64]bool)
@@ -312,15 +411,20 @@ func CheckForProperCsvArray(arr [][]StringHashable) error {
 		s := column.StringHash()
 		key := xxhash.Sum64String(s)
 		if _, exists := marker[key]; exists {
-			return fmt.Errorf("duplicate column: %s", s)
+			return &DuplicateColumnError{Name: s}
 		}
 		marker[key] = true
 	}
 
+	return checkRowLengths(arr)
+}
+
+// Returns nil iff all rows of the non-empty array have as many elements as the first one.
+func checkRowLengths(arr [][]StringHashable) error {
 	length := len(arr[0])
 	for i, row := range arr {
 		if len(row) != length {
-			return fmt.Errorf("row %d has %d columns, expected %d", i, len(row), length)
+			return &RaggedRowError{Row: i, Got: len(row), Want: length}
 		}
 	}
 	return nil
@@ -455,14 +559,23 @@ func IgnoreRows(arr [][]StringHashable, rows []int) ([][]StringHashable, error)
 
 // Helper function for getting all the rows below the columns row for comparison purposes.
 func getBelowComparisonArrays(arr1, arr2 [][]StringHashable, options Options) ([][]StringHashable, [][]StringHashable, error) {
+	options1, err := resolveOptionsColumns(arr1[0], options)
+	if err != nil {
+		return nil, nil, err
+	}
+	options2, err := resolveOptionsColumns(arr2[0], options)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var comparisonArray1 [][]StringHashable
 	var comparisonArray2 [][]StringHashable
 	if options.UseColumns != nil {
-		comparisonArray1, _ = KeepColumns(arr1, options.UseColumns)
-		comparisonArray2, _ = KeepColumns(arr2, options.UseColumns)
+		comparisonArray1, _ = KeepColumns(arr1, options1.UseColumns)
+		comparisonArray2, _ = KeepColumns(arr2, options2.UseColumns)
 	} else if options.IgnoreColumns != nil {
-		comparisonArray1, _ = IgnoreColumns(arr1, options.IgnoreColumns)
-		comparisonArray2, _ = IgnoreColumns(arr2, options.IgnoreColumns)
+		comparisonArray1, _ = IgnoreColumns(arr1, options1.IgnoreColumns)
+		comparisonArray2, _ = IgnoreColumns(arr2, options2.IgnoreColumns)
 	} else {
 		comparisonArray1 = arr1
 		comparisonArray2 = arr2
@@ -471,9 +584,9 @@ func getBelowComparisonArrays(arr1, arr2 [][]StringHashable, options Options) ([
 	columns1 := comparisonArray1[0]
 	columns2 := comparisonArray2[0]
 	if len(columns1) == 0 || len(columns2) == 0 {
-		return nil, nil, fmt.Errorf("no columns to compare")
+		return nil, nil, ErrNoColumnsToCompare
 	} else if !rowsArePermutationsOfEachOther(columns1, columns2) {
-		return nil, nil, fmt.Errorf("check the columns being compared")
+		return nil, nil, newColumnMismatchError(columns1, columns2)
 	}
 
 	comparisonArray2, _ = RearrangeColumns(comparisonArray2, columns1)
@@ -484,82 +597,336 @@ func getBelowComparisonArrays(arr1, arr2 [][]StringHashable, options Options) ([
 	return comparisonArray1, comparisonArray2, nil
 }
 
-// Helper function that adds 1 to all elements of the array.
-func addOneToIntArray(arr []int) []int {
-	res := make([]int, len(arr))
-	copy(res, arr)
-	for i := range res {
-		res[i]++
+// For holding an array prepared for comparison.
+type comparedArray struct {
+	rows    [][]StringHashable // The original array with its ragged rows fixed.
+	indices []int              // The indices in rows of the compared rows below the header.
+	ragged  []int              // The indices of the ragged rows handled by Options.RaggedRows.
+	dates   []int              // The indices of the compared rows with values of Options.DateColumns that could not be parsed.
+	columns []StringHashable   // The compared columns, in the order they are compared in.
+}
+
+// Helper function that fixes the ragged rows of the array according to the options,
+// adds the derived columns and returns it along with the array to compare, which starts
+// with the disambiguated or synthetic header row and leaves out the skipped rows.
+func getComparedArray(csvArray [][]StringHashable, derived []DerivedColumn, options Options) (comparedArray, [][]StringHashable, error) {
+	rows, ragged, err := fixRaggedRows(csvArray, options.RaggedRows)
+	if err != nil {
+		return comparedArray{}, nil, err
 	}
-	return res
+
+	header := GetSyntheticHeader(len(rows[0]))
+	start := 0
+	if !options.NoHeader {
+		header = DisambiguateHeader(rows[0], options.DuplicateColumns)
+		start = 1
+	}
+
+	if len(derived) > 0 {
+		var derivedHeader []StringHashable
+		derivedHeader, rows, err = addDerivedColumns(header, rows, start, derived)
+		if err != nil {
+			return comparedArray{}, nil, err
+		}
+		if !options.NoHeader {
+			rows[0] = append(slices.Clone(rows[0]), derivedHeader[len(header):]...)
+		}
+		header = derivedHeader
+	}
+
+	res := comparedArray{rows: rows, indices: make([]int, 0, len(rows)), ragged: ragged}
+	arr := make([][]StringHashable, 0, len(rows)+1-start)
+	arr = append(arr, header)
+	j := 0
+	for i := start; i < len(rows); i++ {
+		if options.RaggedRows == RaggedRowsSkip && j < len(ragged) && ragged[j] == i {
+			j++
+			continue
+		}
+		res.indices = append(res.indices, i)
+		arr = append(arr, rows[i])
+	}
+
+	err = CheckForProperCsvArray(arr)
+	if err != nil {
+		return comparedArray{}, nil, err
+	}
+	return res, arr, nil
 }
 
-// Returns the common rows between the two arrays based on the
-// given options and the indices of the rows in the results from the
-// original arrays.
-func GetCommonRows(csvArray1, csvArray2 [][]StringHashable, options Options) ([][]StringHashable, [][]StringHashable, []int, []int, error) {
-	err := CheckForProperCsvArray(csvArray1)
+// Helper function that normalizes the date/time columns of the array to compare, leaves
+// out the rows not matching options.Where and sorts the values of the column groups.
+func (c *comparedArray) prepareRows(arr [][]StringHashable, options Options) ([][]StringHashable, error) {
+	arr, unparsed, err := normalizeDateColumns(arr, options.DateColumns)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return nil, err
 	}
-	err = CheckForProperCsvArray(csvArray2)
+	dates := make([]int, len(unparsed))
+	for i, index := range unparsed {
+		dates[i] = c.indices[index-1]
+	}
+
+	if options.Where != "" {
+		filter, err := ParseRowFilter(options.Where)
+		if err != nil {
+			return nil, err
+		}
+		arr, err = filterComparedRows(c, arr, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.dates = []int{}
+	j := 0
+	for _, index := range dates {
+		for j < len(c.indices) && c.indices[j] < index {
+			j++
+		}
+		if j < len(c.indices) && c.indices[j] == index {
+			c.dates = append(c.dates, index)
+		}
+	}
+
+	return sortColumnGroups(arr, options.UnorderedColumns)
+}
+
+// Helper function that validates the inputs and returns the prepared arrays along
+// with the comparison arrays below the columns row.
+func getCheckedComparisonArrays(csvArray1, csvArray2 [][]StringHashable, options Options) (comparedArray, comparedArray, [][]StringHashable, [][]StringHashable, error) {
+	err := options.CheckAttributes()
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return comparedArray{}, comparedArray{}, nil, nil, err
 	}
 
-	err = options.CheckAttributes()
+	compared1, arr1, err := getComparedArray(csvArray1, options.DerivedColumns1, options)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return comparedArray{}, comparedArray{}, nil, nil, err
+	}
+	compared2, arr2, err := getComparedArray(csvArray2, options.DerivedColumns2, options)
+	if err != nil {
+		return comparedArray{}, comparedArray{}, nil, nil, err
 	}
 
-	belowArray1, belowArray2, err := getBelowComparisonArrays(csvArray1, csvArray2, options)
+	arr2, _, err = renameMappedColumns(arr2, options.ColumnMapping)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return comparedArray{}, comparedArray{}, nil, nil, err
 	}
 
-	belowIndices1, belowIndices2, _ := GetCommonIndices(belowArray1, belowArray2, options.Method, options.SortIndices)
+	arr1, err = compared1.prepareRows(arr1, options)
+	if err != nil {
+		return comparedArray{}, comparedArray{}, nil, nil, err
+	}
+	arr2, err = compared2.prepareRows(arr2, options)
+	if err != nil {
+		return comparedArray{}, comparedArray{}, nil, nil, err
+	}
 
-	indices1 := append([]int{0}, addOneToIntArray(belowIndices1)...)
-	indices2 := append([]int{0}, addOneToIntArray(belowIndices2)...)
+	belowArray1, belowArray2, err := getBelowComparisonArrays(arr1, arr2, options)
+	if err != nil {
+		return comparedArray{}, comparedArray{}, nil, nil, err
+	}
+	compared1.columns, _ = getComparedColumns(arr1[0], options)
+	return compared1, compared2, belowArray1, belowArray2, nil
+}
+
+// Returns the indices in the original array of the rows at the given indices
+// below the columns row, preceded by the columns row unless options.NoHeader is set.
+func (c comparedArray) getOriginalIndices(belowIndices []int, options Options) []int {
+	res := make([]int, 0, len(belowIndices)+1)
+	if !options.NoHeader {
+		res = append(res, 0)
+	}
+	for _, i := range belowIndices {
+		res = append(res, c.indices[i])
+	}
+	return res
+}
 
-	res1, _ := KeepRows(csvArray1, indices1)
-	res2, _ := KeepRows(csvArray2, indices2)
+// For holding the indices of the rows found by comparing two csv arrays.
+type rowsIndices struct {
+	compared1 comparedArray
+	compared2 comparedArray
+	indices1  []int     // The indices of the rows found in the first array.
+	indices2  []int     // The indices of the rows found in the second array.
+	pairs     []RowPair // The rows paired by MethodFuzzy, by their indices in the original arrays.
+
+	comparison rowsComparison
+	below1     [][]StringHashable // The compared rows of the first array below the columns row.
+	below2     [][]StringHashable // The compared rows of the second array below the columns row.
+}
 
-	return res1, res2, indices1, indices2, nil
+// Returns the summary of the comparison the rows were found by.
+func (f rowsIndices) getSummary(options Options) (Summary, error) {
+	return getSummary(f.comparison, f.below1, f.below2, f.compared1.columns, options)
 }
 
-// Returns the different rows between the two arrays based on the
-// given options and the indices of the rows in the results from the
-// original arrays.
-func GetDifferentRows(csvArray1, csvArray2 [][]StringHashable, options Options) ([][]StringHashable, [][]StringHashable, []int, []int, error) {
-	err := CheckForProperCsvArray(csvArray1)
+// Helper function for finding the indices of the common rows, or the different rows
+// unless common is set, in the original arrays, including the columns row unless
+// options.NoHeader is set.
+func getRowsIndices(ctx context.Context, csvArray1, csvArray2 [][]StringHashable, options Options, progress ProgressFunc, common bool) (rowsIndices, error) {
+	compared1, compared2, belowArray1, belowArray2, err := getCheckedComparisonArrays(csvArray1, csvArray2, options)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return rowsIndices{}, err
 	}
-	err = CheckForProperCsvArray(csvArray2)
+
+	comparison, err := compareRows(ctx, belowArray1, belowArray2, options.Method, compared1.columns, options.Fuzzy, progress)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return rowsIndices{}, err
+	}
+
+	var pairs []RowPair
+	if options.Method == MethodFuzzy {
+		pairs = make([]RowPair, len(comparison.pairs))
+		for i, pair := range comparison.pairs {
+			pairs[i] = RowPair{Index1: compared1.indices[pair.Index1], Index2: compared2.indices[pair.Index2], Score: pair.Score}
+		}
+	}
+
+	found := rowsIndices{
+		compared1:  compared1,
+		compared2:  compared2,
+		pairs:      pairs,
+		comparison: comparison,
+		below1:     belowArray1,
+		below2:     belowArray2,
 	}
+	return found.withIndices(common, options), nil
+}
+
+// Returns a copy of the found rows holding the indices of the common rows, or the
+// different rows unless common is set, in the original arrays, including the columns
+// row unless options.NoHeader is set.
+func (f rowsIndices) withIndices(common bool, options Options) rowsIndices {
+	belowIndices1, belowIndices2 := f.comparison.different1, f.comparison.different2
+	if common {
+		belowIndices1, belowIndices2 = f.comparison.common1, f.comparison.common2
+	}
+	if options.SortIndices {
+		sort.Ints(belowIndices1)
+		sort.Ints(belowIndices2)
+	}
+	f.indices1 = f.compared1.getOriginalIndices(belowIndices1, options)
+	f.indices2 = f.compared2.getOriginalIndices(belowIndices2, options)
+	return f
+}
+
+// Returns the rows found, with the columns mapped by options.ColumnMapping named by
+// both their left and right names in the columns rows.
+func (f rowsIndices) getRows(options Options) ([][]StringHashable, [][]StringHashable) {
+	res1 := getRowsAt(f.compared1.rows, f.indices1)
+	res2 := getRowsAt(f.compared2.rows, f.indices2)
+	if !options.NoHeader {
+		_, renamed, _ := renameMappedColumns(f.compared2.rows, options.ColumnMapping)
+		setMappedHeaders(res1, res2, renamed)
+	}
+	return res1, res2
+}
 
-	err = options.CheckAttributes()
+// For holding the rows found by comparing two csv arrays.
+type RowsResult struct {
+	Rows1          [][]StringHashable
+	Rows2          [][]StringHashable
+	Indices1       []int     // The indices of Rows1 in the first array.
+	Indices2       []int     // The indices of Rows2 in the second array.
+	RaggedRows1    []int     // The indices of the ragged rows of the first array handled by Options.RaggedRows.
+	RaggedRows2    []int     // The indices of the ragged rows of the second array handled by Options.RaggedRows.
+	UnparsedDates1 []int     // The indices of the rows of the first array with values of Options.DateColumns that could not be parsed, which are compared as they are.
+	UnparsedDates2 []int     // The indices of the rows of the second array with values of Options.DateColumns that could not be parsed, which are compared as they are.
+	Pairs          []RowPair // The rows paired approximately by MethodFuzzy, best scores first.
+	Summary        Summary   // Statistics of the whole comparison, covering both the common and the different rows.
+}
+
+// Helper function for getting the common rows, or the different rows unless common is set.
+// The summary of the comparison is only computed if summarize is set.
+func getRowsResult(ctx context.Context, csvArray1, csvArray2 [][]StringHashable, options Options, progress ProgressFunc, common, summarize bool) (RowsResult, error) {
+	found, err := getRowsIndices(ctx, csvArray1, csvArray2, options, progress, common)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return RowsResult{}, err
+	}
+	var summary Summary
+	if summarize {
+		summary, err = found.getSummary(options)
+		if err != nil {
+			return RowsResult{}, err
+		}
 	}
 
-	belowArray1, belowArray2, err := getBelowComparisonArrays(csvArray1, csvArray2, options)
+	res := RowsResult{
+		Indices1:       found.indices1,
+		Indices2:       found.indices2,
+		RaggedRows1:    found.compared1.ragged,
+		RaggedRows2:    found.compared2.ragged,
+		UnparsedDates1: found.compared1.dates,
+		UnparsedDates2: found.compared2.dates,
+		Pairs:          found.pairs,
+		Summary:        summary,
+	}
+	res.Rows1, res.Rows2 = found.getRows(options)
+	return res, nil
+}
+
+// Returns the common rows between the two arrays based on the
+// given options and the indices of the rows in the results from the
+// original arrays. Columns paired by options.ColumnMapping are named by
+// both their left and right names in the results. With options.NoHeader,
+// the results have no header row either. Otherwise they keep the original
+// header rows, even if options.DuplicateColumns disambiguated them.
+// Ragged rows are padded, truncated or left out according to options.RaggedRows,
+// and only the rows matching options.Where are compared. The results include the
+// columns derived by options.DerivedColumns1 and options.DerivedColumns2, and the
+// original values of the columns normalized by options.DateColumns or sorted by
+// options.UnorderedColumns.
+func GetCommonRows(csvArray1, csvArray2 [][]StringHashable, options Options) ([][]StringHashable, [][]StringHashable, []int, []int, error) {
+	return GetCommonRowsContext(context.Background(), csvArray1, csvArray2, options, nil)
+}
+
+// Returns the common rows between the two arrays like GetCommonRows.
+// Stops with the error of ctx once it is done and reports the progress
+// of the comparison to progress, which may be nil.
+func GetCommonRowsContext(ctx context.Context, csvArray1, csvArray2 [][]StringHashable, options Options, progress ProgressFunc) ([][]StringHashable, [][]StringHashable, []int, []int, error) {
+	res, err := getRowsResult(ctx, csvArray1, csvArray2, options, progress, true, false)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
+	return res.Rows1, res.Rows2, res.Indices1, res.Indices2, nil
+}
 
-	belowIndices1, belowIndices2, _ := GetDifferentIndices(belowArray1, belowArray2, options.Method, options.SortIndices)
+// Returns the common rows between the two arrays like GetCommonRowsContext,
+// along with the indices of the ragged rows handled by options.RaggedRows.
+func GetCommonRowsResult(ctx context.Context, csvArray1, csvArray2 [][]StringHashable, options Options, progress ProgressFunc) (RowsResult, error) {
+	return getRowsResult(ctx, csvArray1, csvArray2, options, progress, true, true)
+}
 
-	indices1 := append([]int{0}, addOneToIntArray(belowIndices1)...)
-	indices2 := append([]int{0}, addOneToIntArray(belowIndices2)...)
+// Returns the different rows between the two arrays based on the
+// given options and the indices of the rows in the results from the
+// original arrays. Columns paired by options.ColumnMapping are named by
+// both their left and right names in the results. With options.NoHeader,
+// the results have no header row either. Otherwise they keep the original
+// header rows, even if options.DuplicateColumns disambiguated them.
+// Ragged rows are padded, truncated or left out according to options.RaggedRows,
+// and only the rows matching options.Where are compared. The results include the
+// columns derived by options.DerivedColumns1 and options.DerivedColumns2, and the
+// original values of the columns normalized by options.DateColumns or sorted by
+// options.UnorderedColumns.
+func GetDifferentRows(csvArray1, csvArray2 [][]StringHashable, options Options) ([][]StringHashable, [][]StringHashable, []int, []int, error) {
+	return GetDifferentRowsContext(context.Background(), csvArray1, csvArray2, options, nil)
+}
 
-	res1, _ := KeepRows(csvArray1, indices1)
-	res2, _ := KeepRows(csvArray2, indices2)
+// Returns the different rows between the two arrays like GetDifferentRows.
+// Stops with the error of ctx once it is done and reports the progress
+// of the comparison to progress, which may be nil.
+func GetDifferentRowsContext(ctx context.Context, csvArray1, csvArray2 [][]StringHashable, options Options, progress ProgressFunc) ([][]StringHashable, [][]StringHashable, []int, []int, error) {
+	res, err := getRowsResult(ctx, csvArray1, csvArray2, options, progress, false, false)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return res.Rows1, res.Rows2, res.Indices1, res.Indices2, nil
+}
 
-	return res1, res2, indices1, indices2, nil
+// Returns the different rows between the two arrays like GetDifferentRowsContext,
+// along with the indices of the ragged rows handled by options.RaggedRows.
+func GetDifferentRowsResult(ctx context.Context, csvArray1, csvArray2 [][]StringHashable, options Options, progress ProgressFunc) (RowsResult, error) {
+	return getRowsResult(ctx, csvArray1, csvArray2, options, progress, false, true)
 }
 
 // Returns a csv array with the columns rearranged accordingly.
@@ -569,23 +936,23 @@ func RearrangeColumns(arr [][]StringHashable, columns []StringHashable) ([][]Str
 		return nil, err
 	}
 
-	marker := make(map[uint64]bool)
-	for _, column := range columns {
-		marker[getStringKey(column)] = true
+	mismatchErr := newColumnMismatchError(arr[0], columns)
+	if len(mismatchErr.OnlyLeft) > 0 || len(mismatchErr.OnlyRight) > 0 {
+		return nil, mismatchErr
 	}
 
 	mapping := make(map[uint64]int)
 	for i, column := range arr[0] {
-		s := column.StringHash()
-		stringHash := xxhash.Sum64String(s)
-		if _, exists := marker[stringHash]; !exists {
-			return nil, fmt.Errorf("column %s not found", s)
-		}
-		mapping[stringHash] = i
+		mapping[getStringKey(column)] = i
 	}
 
-	if len(mapping) != len(columns) {
-		return nil, fmt.Errorf("columns must use the same names")
+	marker := make(map[uint64]bool)
+	for _, column := range columns {
+		key := getStringKey(column)
+		if marker[key] {
+			return nil, &DuplicateColumnError{Name: column.StringHash()}
+		}
+		marker[key] = true
 	}
 
 	columnsStringHashes := make([]uint64, len(columns))
@@ -0,0 +1,157 @@
+package csvcheck
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Supported granularities of date/time comparisons, from finest to coarsest.
+const (
+	DateGranularityMillisecond = iota
+	DateGranularitySecond
+	DateGranularityMinute
+	DateGranularityDay
+)
+
+// The layouts tried in order when a DateColumn has none, in the format of the time package.
+var DefaultDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006 15:04:05",
+	"01/02/2006",
+	"20060102",
+}
+
+// For holding how the values of a date/time column are normalized before comparing,
+// so that values written differently but referring to the same instant are equal.
+type DateColumn struct {
+	Layouts     []string       // The layouts tried in order, in the format of the time package. Defaults to DefaultDateLayouts.
+	TimeZone    *time.Location // Values with an offset are converted to this zone, and values without one are taken to be in it. Defaults to UTC.
+	Granularity int            // Values are truncated to this granularity, such as DateGranularityDay.
+}
+
+// Returns the granularity with the given name.
+func ParseDateGranularity(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "", "ms", "millisecond":
+		return DateGranularityMillisecond, nil
+	case "s", "second":
+		return DateGranularitySecond, nil
+	case "min", "minute":
+		return DateGranularityMinute, nil
+	case "day":
+		return DateGranularityDay, nil
+	}
+	return 0, &InvalidOptionError{Option: "date granularity", Value: s}
+}
+
+// Checks if the options are valid.
+func (d *DateColumn) CheckAttributes() error {
+	if d.Granularity < DateGranularityMillisecond || d.Granularity > DateGranularityDay {
+		return &InvalidOptionError{Option: "date granularity", Value: fmt.Sprint(d.Granularity)}
+	}
+	return nil
+}
+
+// Returns the value as a date/time in a canonical form according to the column,
+// such as "2024-01-02" with DateGranularityDay or "2024-01-02T03:04:05Z" with
+// DateGranularitySecond, and true, or the value and false if it cannot be parsed.
+func NormalizeDate(value string, column DateColumn) (string, bool) {
+	layouts := column.Layouts
+	if len(layouts) == 0 {
+		layouts = DefaultDateLayouts
+	}
+	location := column.TimeZone
+	if location == nil {
+		location = time.UTC
+	}
+
+	s := strings.TrimSpace(value)
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, s, location)
+		if err != nil {
+			continue
+		}
+		t = t.In(location)
+		switch column.Granularity {
+		case DateGranularityDay:
+			return t.Format(time.DateOnly), true
+		case DateGranularityMinute:
+			return t.Truncate(time.Minute).Format("2006-01-02T15:04Z07:00"), true
+		case DateGranularitySecond:
+			return t.Truncate(time.Second).Format(time.RFC3339), true
+		}
+		return t.Truncate(time.Millisecond).Format("2006-01-02T15:04:05.000Z07:00"), true
+	}
+	return value, false
+}
+
+// Returns a shallow copy of the array with the values of the date/time columns below
+// the header normalized by NormalizeDate, along with the indices of the rows having
+// values that could not be parsed, which are left as they are. Empty values are left
+// empty. Returns a ColumnNotFoundError for columns missing from the header.
+func NormalizeDateColumns(arr [][]StringHashable, columns map[string]DateColumn) ([][]StringHashable, []int, error) {
+	err := CheckForProperCsvArray(arr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return normalizeDateColumns(arr, columns)
+}
+
+// Returns the array with its date/time columns normalized like NormalizeDateColumns,
+// without checking it. Returns the array itself if there are no such columns.
+func normalizeDateColumns(arr [][]StringHashable, columns map[string]DateColumn) ([][]StringHashable, []int, error) {
+	if len(columns) == 0 {
+		return arr, []int{}, nil
+	}
+
+	indices := make([]int, 0, len(columns))
+	dateColumns := make([]DateColumn, 0, len(columns))
+	for name, column := range columns {
+		err := column.CheckAttributes()
+		if err != nil {
+			return nil, nil, err
+		}
+		index := -1
+		for i, c := range arr[0] {
+			if c.StringHash() == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, nil, &ColumnNotFoundError{Name: name}
+		}
+		indices = append(indices, index)
+		dateColumns = append(dateColumns, column)
+	}
+
+	res := make([][]StringHashable, len(arr))
+	res[0] = arr[0]
+	unparsed := []int{}
+	for i := 1; i < len(arr); i++ {
+		row := make([]StringHashable, len(arr[i]))
+		copy(row, arr[i])
+		failed := false
+		for j, index := range indices {
+			if strings.TrimSpace(row[index].StringHash()) == "" {
+				continue
+			}
+			value, ok := NormalizeDate(row[index].StringHash(), dateColumns[j])
+			if !ok {
+				failed = true
+				continue
+			}
+			row[index] = BasicStringHashable(value)
+		}
+		if failed {
+			unparsed = append(unparsed, i)
+		}
+		res[i] = row
+	}
+	return res, unparsed, nil
+}
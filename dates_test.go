@@ -0,0 +1,149 @@
+package csvcheck_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeDate(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	tests := []struct {
+		value    string
+		column   csvcheck.DateColumn
+		expected string
+	}{
+		{"2024-01-02", csvcheck.DateColumn{Granularity: csvcheck.DateGranularityDay}, "2024-01-02"},
+		{"01/02/2024", csvcheck.DateColumn{Granularity: csvcheck.DateGranularityDay}, "2024-01-02"},
+		{"2024-01-02T23:30:00-02:00", csvcheck.DateColumn{Granularity: csvcheck.DateGranularityDay}, "2024-01-03"},
+		{"2024-01-02T23:30:00-02:00", csvcheck.DateColumn{Granularity: csvcheck.DateGranularityDay, TimeZone: time.FixedZone("", -3*60*60)}, "2024-01-02"},
+		{"2024-01-02T10:00:00.123456Z", csvcheck.DateColumn{}, "2024-01-02T10:00:00.123Z"},
+		{"2024-01-02T10:00:00.123456Z", csvcheck.DateColumn{Granularity: csvcheck.DateGranularitySecond}, "2024-01-02T10:00:00Z"},
+		{"2024-01-02T10:00:00Z", csvcheck.DateColumn{Granularity: csvcheck.DateGranularitySecond, TimeZone: tokyo}, "2024-01-02T19:00:00+09:00"},
+		{"2024-01-02 19:00:00", csvcheck.DateColumn{Granularity: csvcheck.DateGranularitySecond, TimeZone: tokyo}, "2024-01-02T19:00:00+09:00"},
+		{"2024-01-02 19:00:59", csvcheck.DateColumn{Granularity: csvcheck.DateGranularityMinute}, "2024-01-02T19:00Z"},
+		{"2.1.2024", csvcheck.DateColumn{Layouts: []string{"2.1.2006"}, Granularity: csvcheck.DateGranularityDay}, "2024-01-02"},
+	}
+	for _, test := range tests {
+		res, ok := csvcheck.NormalizeDate(test.value, test.column)
+		assert.True(t, ok, test.value)
+		assert.Equal(t, test.expected, res, test.value)
+	}
+
+	res, ok := csvcheck.NormalizeDate("yesterday", csvcheck.DateColumn{})
+	assert.False(t, ok)
+	assert.Equal(t, "yesterday", res)
+}
+
+func TestNormalizeDateColumns(t *testing.T) {
+	arr := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "created"},
+		{"1", "01/02/2024"},
+		{"2", "soon"},
+		{"3", ""},
+	})
+	columns := map[string]csvcheck.DateColumn{"created": {Granularity: csvcheck.DateGranularityDay}}
+
+	res, unparsed, err := csvcheck.NormalizeDateColumns(arr, columns)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2}, unparsed)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "created"},
+		{"1", "2024-01-02"},
+		{"2", "soon"},
+		{"3", ""},
+	}), res)
+	assert.Equal(t, "01/02/2024", arr[1][1].StringHash())
+
+	_, _, err = csvcheck.NormalizeDateColumns(arr, map[string]csvcheck.DateColumn{"updated": {}})
+	var notFound *csvcheck.ColumnNotFoundError
+	assert.True(t, errors.As(err, &notFound))
+
+	_, _, err = csvcheck.NormalizeDateColumns(arr, map[string]csvcheck.DateColumn{"created": {Granularity: -1}})
+	assert.NotNil(t, err)
+}
+
+func TestGetDifferentRowsDateColumns(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "created"},
+		{"1", "2024-01-02"},
+		{"2", "2024-01-03T10:00:00+02:00"},
+		{"3", "2024-01-04T10:00:00.5Z"},
+	})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "when"},
+		{"1", "01/02/2024"},
+		{"2", "2024-01-03T08:00:00Z"},
+		{"3", "2024-01-04T10:00:00Z"},
+	})
+	options := csvcheck.Options{
+		SortIndices:   true,
+		ColumnMapping: map[string]string{"created": "when"},
+		DateColumns:   map[string]csvcheck.DateColumn{"created": {}},
+	}
+
+	res1, _, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 3}, indices1)
+	assert.Equal(t, []int{0, 3}, indices2)
+	assert.Equal(t, "2024-01-04T10:00:00.5Z", res1[1][1].StringHash())
+
+	options.DateColumns = map[string]csvcheck.DateColumn{"created": {Granularity: csvcheck.DateGranularitySecond}}
+	_, _, indices1, _, err = csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0}, indices1)
+
+	options.DateColumns = map[string]csvcheck.DateColumn{"created": {Granularity: 10}}
+	_, _, _, _, err = csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.NotNil(t, err)
+}
+
+func TestGetDifferentRowsResultUnparsedDates(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "created"},
+		{"1", "2024-01-02"},
+		{"2", "soon"},
+		{"3", "2024-01-04"},
+		{"4", "never"},
+	})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "created"},
+		{"1", "01/02/2024"},
+		{"2", "soon"},
+		{"3", ""},
+	})
+	options := csvcheck.Options{
+		SortIndices: true,
+		Where:       "id != 4",
+		DateColumns: map[string]csvcheck.DateColumn{"created": {}},
+	}
+
+	res, err := csvcheck.GetDifferentRowsResult(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2}, res.UnparsedDates1)
+	assert.Equal(t, []int{2}, res.UnparsedDates2)
+	assert.Equal(t, []int{0, 3}, res.Indices1)
+
+	options.DateColumns = nil
+	res, err = csvcheck.GetDifferentRowsResult(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{}, res.UnparsedDates1)
+}
+
+func TestParseDateGranularity(t *testing.T) {
+	res, err := csvcheck.ParseDateGranularity("day")
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.DateGranularityDay, res)
+
+	res, err = csvcheck.ParseDateGranularity("second")
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.DateGranularitySecond, res)
+
+	_, err = csvcheck.ParseDateGranularity("week")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,342 @@
+package csvcheck
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// For holding a column computed from the other columns of each row, such as
+// {Name: "full_name", Expr: `concat(first_name, " ", last_name)`} or
+// {Name: "amount", Expr: `round(amount_cents / 100, 2)`}.
+//
+// Expressions combine column names, string literals in double or single quotes and
+// numbers with the arithmetic operators +, -, * and / and the functions below.
+// Column names that are not plain identifiers can be written in backquotes. Values
+// that cannot be computed, such as the sum of non-numeric values, are empty.
+//
+//	concat(a, b, ...)       the values joined together
+//	upper(s), lower(s)      s in upper or lower case
+//	trim(s)                 s without surrounding whitespace
+//	substr(s, start, n)     n characters of s from the zero based start, or all of them without n
+//	replace(s, old, new)    s with every old replaced by new
+//	round(x, digits)        x rounded to the given number of decimals, keeping trailing zeros
+//	date(s, from, to)       the date s in the layout from reformatted to the layout to,
+//	                        both in the format of the time package such as "2006-01-02"
+type DerivedColumn struct {
+	Name string
+	Expr string
+}
+
+// For holding a node of a parsed value expression: a literal, a column,
+// an arithmetic operator or a function call.
+type valueNode struct {
+	op    string // "literal", "column", an arithmetic operator or the name of a function.
+	value string
+	args  []*valueNode
+}
+
+// The number of arguments each function of value expressions accepts, at least and at most.
+var valueFunctions = map[string][2]int{
+	"concat":  {1, math.MaxInt},
+	"upper":   {1, 1},
+	"lower":   {1, 1},
+	"trim":    {1, 1},
+	"substr":  {2, 3},
+	"replace": {3, 3},
+	"round":   {2, 2},
+	"date":    {3, 3},
+}
+
+// Parses a sum or difference of terms, which binds the loosest.
+func (p *filterParser) parseValue() (*valueNode, error) {
+	return p.parseArithmetic([]string{"+", "-"}, p.parseTerm)
+}
+
+// Parses a product or quotient of factors.
+func (p *filterParser) parseTerm() (*valueNode, error) {
+	return p.parseArithmetic([]string{"*", "/"}, p.parseFactor)
+}
+
+// Parses operands joined by any of the given arithmetic operators, from left to right.
+func (p *filterParser) parseArithmetic(ops []string, parseNext func() (*valueNode, error)) (*valueNode, error) {
+	res, err := parseNext()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, candidate := range ops {
+			if p.accept(candidate) {
+				op = candidate
+				break
+			}
+		}
+		if op == "" {
+			return res, nil
+		}
+		right, err := parseNext()
+		if err != nil {
+			return nil, err
+		}
+		res = &valueNode{op: op, args: []*valueNode{res, right}}
+	}
+}
+
+// Parses a negation, a parenthesized value, a function call, a column name, a string or a number.
+func (p *filterParser) parseFactor() (*valueNode, error) {
+	if p.accept("-") {
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return &valueNode{op: "-", args: []*valueNode{{op: "literal", value: "0"}, operand}}, nil
+	}
+	if p.accept("(") {
+		res, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.unexpected()
+		}
+		return res, nil
+	}
+
+	t := p.peek()
+	switch t.kind {
+	case filterTokenString, filterTokenNumber:
+		p.i++
+		return &valueNode{op: "literal", value: t.value}, nil
+	case filterTokenColumn:
+		p.i++
+		if next := p.peek(); next.kind != filterTokenOperator || next.value != "(" {
+			return &valueNode{op: "column", value: t.value}, nil
+		}
+		return p.parseCall(t)
+	}
+	return nil, p.unexpected()
+}
+
+// Parses the arguments of a call to the function named by t.
+func (p *filterParser) parseCall(t filterToken) (*valueNode, error) {
+	name := strings.ToLower(t.value)
+	arity, ok := valueFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at position %d", t.value, t.pos)
+	}
+
+	p.accept("(")
+	res := &valueNode{op: name}
+	for !p.accept(")") {
+		if len(res.args) > 0 && !p.accept(",") {
+			return nil, p.unexpected()
+		}
+		arg, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		res.args = append(res.args, arg)
+	}
+	if len(res.args) < arity[0] || len(res.args) > arity[1] {
+		return nil, fmt.Errorf("wrong number of arguments to %s at position %d", t.value, t.pos)
+	}
+	return res, nil
+}
+
+// Returns the parsed value expression.
+func parseValueExpression(expr string) (*valueNode, error) {
+	tokens, err := getFilterTokens(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", expr, err)
+	}
+
+	p := filterParser{tokens: tokens}
+	res, err := p.parseValue()
+	if err == nil && p.peek().kind != filterTokenEnd {
+		err = p.unexpected()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", expr, err)
+	}
+	return res, nil
+}
+
+// Returns a number formatted without trailing zeros, or an empty string if it is not finite.
+func formatNumber(x float64) string {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return ""
+	}
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+// Returns the value of a function of value expressions for the given argument values.
+func callValueFunction(name string, args []string) string {
+	switch name {
+	case "concat":
+		return strings.Join(args, "")
+	case "upper":
+		return strings.ToUpper(args[0])
+	case "lower":
+		return strings.ToLower(args[0])
+	case "trim":
+		return strings.TrimSpace(args[0])
+	case "replace":
+		return strings.ReplaceAll(args[0], args[1], args[2])
+	case "substr":
+		runes := []rune(args[0])
+		start, err := strconv.Atoi(strings.TrimSpace(args[1]))
+		if err != nil || start < 0 {
+			return ""
+		}
+		start = min(start, len(runes))
+		end := len(runes)
+		if len(args) == 3 {
+			n, err := strconv.Atoi(strings.TrimSpace(args[2]))
+			if err != nil || n < 0 {
+				return ""
+			}
+			end = min(start+n, end)
+		}
+		return string(runes[start:end])
+	case "round":
+		x, err := strconv.ParseFloat(strings.TrimSpace(args[0]), 64)
+		digits, err2 := strconv.Atoi(strings.TrimSpace(args[1]))
+		if err != nil || err2 != nil || digits < 0 {
+			return ""
+		}
+		return strconv.FormatFloat(x, 'f', digits, 64)
+	case "date":
+		t, err := time.Parse(args[1], strings.TrimSpace(args[0]))
+		if err != nil {
+			return ""
+		}
+		return t.Format(args[2])
+	}
+	return ""
+}
+
+// Returns a function computing the value of the node for a row with the given header.
+// Returns a ColumnNotFoundError if the node refers to a column missing from the header.
+func (n *valueNode) compile(header []StringHashable) (func([]StringHashable) string, error) {
+	switch n.op {
+	case "literal":
+		return func([]StringHashable) string { return n.value }, nil
+	case "column":
+		for i, column := range header {
+			if column.StringHash() == n.value {
+				return func(row []StringHashable) string { return row[i].StringHash() }, nil
+			}
+		}
+		return nil, &ColumnNotFoundError{Name: n.value}
+	}
+
+	args := make([]func([]StringHashable) string, len(n.args))
+	for i, arg := range n.args {
+		var err error
+		args[i], err = arg.compile(header)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch n.op {
+	case "+", "-", "*", "/":
+		op := n.op
+		return func(row []StringHashable) string {
+			x, err := strconv.ParseFloat(strings.TrimSpace(args[0](row)), 64)
+			if err != nil {
+				return ""
+			}
+			y, err := strconv.ParseFloat(strings.TrimSpace(args[1](row)), 64)
+			if err != nil {
+				return ""
+			}
+			switch op {
+			case "+":
+				return formatNumber(x + y)
+			case "-":
+				return formatNumber(x - y)
+			case "*":
+				return formatNumber(x * y)
+			}
+			return formatNumber(x / y)
+		}, nil
+	}
+
+	name := n.op
+	return func(row []StringHashable) string {
+		values := make([]string, len(args))
+		for i, arg := range args {
+			values[i] = arg(row)
+		}
+		return callValueFunction(name, values)
+	}, nil
+}
+
+// Returns the header and rows with the derived columns appended. Each derived column
+// can refer to the columns of the header and the derived columns before it. Rows
+// above start and rows with a different number of cells than the header, such as
+// skipped ragged rows, are left as they are.
+func addDerivedColumns(header []StringHashable, rows [][]StringHashable, start int, columns []DerivedColumn) ([]StringHashable, [][]StringHashable, error) {
+	newHeader := make([]StringHashable, len(header), len(header)+len(columns))
+	copy(newHeader, header)
+	values := make([]func([]StringHashable) string, len(columns))
+	for i, column := range columns {
+		node, err := parseValueExpression(column.Expr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("derived column %s: %w", column.Name, err)
+		}
+		values[i], err = node.compile(newHeader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("derived column %s: %w", column.Name, err)
+		}
+		newHeader = append(newHeader, BasicStringHashable(column.Name))
+	}
+
+	res := make([][]StringHashable, len(rows))
+	copy(res, rows)
+	for i := start; i < len(rows); i++ {
+		if len(rows[i]) != len(header) {
+			continue
+		}
+		row := make([]StringHashable, len(header), len(header)+len(columns))
+		copy(row, rows[i])
+		for _, value := range values {
+			row = append(row, BasicStringHashable(value(row)))
+		}
+		res[i] = row
+	}
+	return newHeader, res, nil
+}
+
+// Returns a copy of the header row with the names of the derived columns appended.
+func appendDerivedColumnNames(header []StringHashable, columns []DerivedColumn) []StringHashable {
+	res := slices.Clone(header)
+	for _, column := range columns {
+		res = append(res, BasicStringHashable(column.Name))
+	}
+	return res
+}
+
+// Returns a copy of the array with the derived columns appended to every row.
+func AddDerivedColumns(arr [][]StringHashable, columns []DerivedColumn) ([][]StringHashable, error) {
+	err := CheckForProperCsvArray(arr)
+	if err != nil {
+		return nil, err
+	}
+
+	header, res, err := addDerivedColumns(arr[0], arr, 1, columns)
+	if err != nil {
+		return nil, err
+	}
+	res[0] = header
+	err = CheckForProperCsvArray(res[:1])
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,121 @@
+package csvcheck_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getPeopleCsvArray() [][]csvcheck.StringHashable {
+	return csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"first_name", "last_name", "amount_cents", "born"},
+		{"Ada", "Lovelace", "1250", "12/10/1815"},
+		{"alan", "Turing", "99", "06/23/1912"},
+		{"Grace", "Hopper", "x", "1906-12-09"},
+	})
+}
+
+func TestAddDerivedColumns(t *testing.T) {
+	arr := getPeopleCsvArray()
+	columns := []csvcheck.DerivedColumn{
+		{Name: "full_name", Expr: `concat(first_name, " ", last_name)`},
+		{Name: "amount", Expr: `round(amount_cents / 100, 2)`},
+		{Name: "born_iso", Expr: `date(born, "01/02/2006", "2006-01-02")`},
+		{Name: "initials", Expr: `upper(concat(substr(first_name, 0, 1), substr(last_name, 0, 1)))`},
+		{Name: "calc", Expr: `-(amount_cents - 50) * 2 + 1`},
+		{Name: "shout", Expr: `replace(lower(full_name), " ", "_")`},
+		{Name: "rest", Expr: "trim(substr(`first_name`, 1))"},
+	}
+
+	res, err := csvcheck.AddDerivedColumns(arr, columns)
+
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"first_name", "last_name", "amount_cents", "born", "full_name", "amount", "born_iso", "initials", "calc", "shout", "rest"},
+		{"Ada", "Lovelace", "1250", "12/10/1815", "Ada Lovelace", "12.50", "1815-12-10", "AL", "-2399", "ada_lovelace", "da"},
+		{"alan", "Turing", "99", "06/23/1912", "alan Turing", "0.99", "1912-06-23", "AT", "-97", "alan_turing", "lan"},
+		{"Grace", "Hopper", "x", "1906-12-09", "Grace Hopper", "", "", "GH", "", "grace_hopper", "race"},
+	}), res)
+	assert.Len(t, arr[0], 4)
+}
+
+func TestAddDerivedColumnsInvalid(t *testing.T) {
+	arr := getPeopleCsvArray()
+	invalid := []string{
+		`concat(first_name`,
+		`concat(first_name,)`,
+		`unknown(first_name)`,
+		`upper(first_name, last_name)`,
+		`first_name +`,
+		`first_name last_name`,
+	}
+	for _, expr := range invalid {
+		_, err := csvcheck.AddDerivedColumns(arr, []csvcheck.DerivedColumn{{Name: "x", Expr: expr}})
+		assert.NotNil(t, err, expr)
+	}
+
+	_, err := csvcheck.AddDerivedColumns(arr, []csvcheck.DerivedColumn{{Name: "x", Expr: `upper(middle_name)`}})
+	var notFound *csvcheck.ColumnNotFoundError
+	assert.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "middle_name", notFound.Name)
+
+	_, err = csvcheck.AddDerivedColumns(arr, []csvcheck.DerivedColumn{{Name: "born", Expr: `born`}})
+	var duplicate *csvcheck.DuplicateColumnError
+	assert.True(t, errors.As(err, &duplicate))
+}
+
+func TestGetDifferentRowsDerivedColumns(t *testing.T) {
+	arr1 := getPeopleCsvArray()
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"full_name", "amount"},
+		{"Ada Lovelace", "12.50"},
+		{"Alan Turing", "0.99"},
+		{"Grace Hopper", ""},
+	})
+	options := csvcheck.Options{
+		SortIndices: true,
+		UseColumns:  csvcheck.GetRowFromRow([]string{"full_name", "amount"}),
+		DerivedColumns1: []csvcheck.DerivedColumn{
+			{Name: "full_name", Expr: `concat(first_name, " ", last_name)`},
+			{Name: "amount", Expr: `round(amount_cents / 100, 2)`},
+		},
+	}
+
+	res1, _, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2}, indices1)
+	assert.Equal(t, []int{0, 2}, indices2)
+	assert.Equal(t, "full_name", res1[0][4].StringHash())
+	assert.Equal(t, "alan Turing", res1[1][4].StringHash())
+
+	report, err := csvcheck.ReconcileHeaders(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.True(t, report.Ok())
+
+	options.DerivedColumns2 = []csvcheck.DerivedColumn{{Name: "full_name", Expr: `full_name`}}
+	_, _, _, _, err = csvcheck.GetDifferentRows(arr1, arr2, options)
+	var duplicate *csvcheck.DuplicateColumnError
+	assert.True(t, errors.As(err, &duplicate))
+}
+
+func TestFilterRowsValueExpressions(t *testing.T) {
+	arr := getPeopleCsvArray()
+	tests := []struct {
+		where    string
+		expected []int
+	}{
+		{`amount_cents / 100 > 1`, []int{1}},
+		{`(amount_cents + 1) == 100`, []int{2}},
+		{`lower(first_name) == "alan" && (amount_cents - 1) * 2 > 0`, []int{2}},
+		{`substr(born, 0, 4) == "1906"`, []int{3}},
+		{`amount_cents-99 == 0`, []int{2}},
+	}
+	for _, test := range tests {
+		res, err := csvcheck.FilterRows(arr, test.where)
+		assert.Nil(t, err, test.where)
+		assert.Equal(t, test.expected, res, test.where)
+	}
+}
@@ -0,0 +1,452 @@
+package csvcheck
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The number of bytes read from a file for sniffing its dialect.
+const SniffSampleSize = 64 * 1024
+
+// For describing how a delimited file is laid out.
+type Dialect struct {
+	Delimiter  rune
+	Quote      rune
+	HasHeader  bool   // Whether the first record is a header row.
+	LineEnding string // Used when writing. Both "\n" and "\r\n" are accepted when reading.
+}
+
+// Commonly used dialects.
+var (
+	DialectCsv       = Dialect{Delimiter: ',', Quote: '"', HasHeader: true, LineEnding: "\n"}
+	DialectTsv       = Dialect{Delimiter: '\t', Quote: '"', HasHeader: true, LineEnding: "\n"}
+	DialectPipe      = Dialect{Delimiter: '|', Quote: '"', HasHeader: true, LineEnding: "\n"}
+	DialectSemicolon = Dialect{Delimiter: ';', Quote: '"', HasHeader: true, LineEnding: "\n"}
+)
+
+// Delimiters and quote characters tried by SniffDialect, in order of preference.
+var (
+	sniffDelimiters = []rune{',', '\t', ';', '|', ':'}
+	sniffQuotes     = []rune{'"', '\''}
+)
+
+// Checks if the dialect is valid.
+func (d *Dialect) CheckAttributes() error {
+	if d.Delimiter == 0 || d.Delimiter == '\r' || d.Delimiter == '\n' {
+		return fmt.Errorf("invalid delimiter: %q", d.Delimiter)
+	}
+	if d.Quote == '\r' || d.Quote == '\n' {
+		return fmt.Errorf("invalid quote character: %q", d.Quote)
+	}
+	if d.Delimiter == d.Quote {
+		return fmt.Errorf("delimiter and quote character must differ")
+	}
+	if d.LineEnding != "" && d.LineEnding != "\n" && d.LineEnding != "\r\n" {
+		return fmt.Errorf("unsupported line ending: %q", d.LineEnding)
+	}
+	return nil
+}
+
+// Reads all the records of a UTF-8 delimited stream into a csv array. Blank lines are skipped.
+// A Quote of 0 disables quoting. Invalid byte sequences are reported with their row and column.
+func ReadCsvArray(r io.Reader, dialect Dialect) ([][]StringHashable, error) {
+	err := dialect.CheckAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	res := [][]StringHashable{}
+	row := []StringHashable{}
+	var field strings.Builder
+	inQuotes := false
+	quoted := false
+	line := 1
+	quoteLine := 0
+
+	endField := func() {
+		row = append(row, BasicStringHashable(field.String()))
+		field.Reset()
+		quoted = false
+	}
+
+	for {
+		c, size, err := br.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if c == utf8.RuneError && size == 1 {
+			return nil, fmt.Errorf("row %d column %d: invalid byte sequence", len(res), len(row))
+		}
+
+		if inQuotes {
+			if c == dialect.Quote {
+				next, _, err := br.ReadRune()
+				if err == nil && next == dialect.Quote {
+					field.WriteRune(c)
+					continue
+				} else if err == nil {
+					br.UnreadRune()
+				}
+				inQuotes = false
+				continue
+			}
+			if c == '\n' {
+				line++
+			}
+			field.WriteRune(c)
+			continue
+		}
+
+		switch {
+		case dialect.Quote != 0 && c == dialect.Quote && field.Len() == 0 && !quoted:
+			inQuotes = true
+			quoted = true
+			quoteLine = line
+		case c == dialect.Delimiter:
+			endField()
+		case c == '\r' || c == '\n':
+			if c == '\r' {
+				next, _, err := br.ReadRune()
+				if err == nil && next != '\n' {
+					br.UnreadRune()
+				}
+			}
+			if len(row) > 0 || field.Len() > 0 || quoted {
+				endField()
+				res = append(res, row)
+				row = []StringHashable{}
+			}
+			line++
+		default:
+			field.WriteRune(c)
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("line %d: unterminated quoted field", quoteLine)
+	}
+	if len(row) > 0 || field.Len() > 0 || quoted {
+		endField()
+		res = append(res, row)
+	}
+	return res, nil
+}
+
+// Returns true iff the cell has to be quoted to be written with the dialect.
+func needsQuoting(s string, dialect Dialect) bool {
+	if s == "" {
+		return false
+	}
+	if strings.ContainsRune(s, dialect.Delimiter) || strings.ContainsAny(s, "\r\n") {
+		return true
+	}
+	if dialect.Quote != 0 && strings.ContainsRune(s, dialect.Quote) {
+		return true
+	}
+	return s[0] == ' ' || s[0] == '\t'
+}
+
+// Writes a csv array to w using the dialect. Cells are quoted only when necessary.
+func WriteCsvArray(w io.Writer, csvArray [][]StringHashable, dialect Dialect) error {
+	return WriteCsvRows(w, slices.All(csvArray), dialect)
+}
+
+// Writes the rows yielded by an iterator, such as the ones returned by IterDifferentRows,
+// to w using the dialect. Cells are quoted only when necessary. Errors in the rows are
+// reported with the indices yielded alongside them.
+func WriteCsvRows(w io.Writer, rows iter.Seq2[int, []StringHashable], dialect Dialect) error {
+	err := dialect.CheckAttributes()
+	if err != nil {
+		return err
+	}
+
+	lineEnding := dialect.LineEnding
+	if lineEnding == "" {
+		lineEnding = "\n"
+	}
+	quote := string(dialect.Quote)
+
+	bw := bufio.NewWriter(w)
+	for i, row := range rows {
+		for j, cell := range row {
+			if j > 0 {
+				bw.WriteRune(dialect.Delimiter)
+			}
+			s := cell.StringHash()
+			if needsQuoting(s, dialect) {
+				if dialect.Quote == 0 {
+					return fmt.Errorf("row %d column %d: cell must be quoted but quoting is disabled", i, j)
+				}
+				s = quote + strings.ReplaceAll(s, quote, quote+quote) + quote
+			}
+			bw.WriteString(s)
+		}
+		bw.WriteString(lineEnding)
+	}
+	return bw.Flush()
+}
+
+// Returns the mode of the number of fields per record and how consistently
+// the records follow it.
+func getFieldCountConsistency(records [][]StringHashable) (int, float64) {
+	counts := make(map[int]int)
+	for _, record := range records {
+		counts[len(record)]++
+	}
+
+	mode := 0
+	for length, count := range counts {
+		if count > counts[mode] || (count == counts[mode] && length > mode) {
+			mode = length
+		}
+	}
+	return mode, float64(counts[mode]) / float64(len(records))
+}
+
+// Returns true iff s parses as a number.
+func isNumeric(s string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
+
+// Guesses whether the first record is a header by comparing it with the records below it.
+// Columns whose values below are all numeric vote for a header iff the first value is not
+// numeric. Columns whose values below all have the same length vote for a header iff the
+// first value has a different length. A header is assumed when there is no evidence either way.
+func sniffHeader(records [][]StringHashable) bool {
+	if len(records) < 2 {
+		return true
+	}
+
+	votes := 0
+	for j, column := range records[0] {
+		numeric := true
+		length := -1
+		for _, record := range records[1:] {
+			if j >= len(record) {
+				numeric = false
+				length = -2
+				break
+			}
+			s := record[j].StringHash()
+			numeric = numeric && isNumeric(s)
+			if length == -1 {
+				length = len(s)
+			} else if length != len(s) {
+				length = -2
+			}
+		}
+
+		s := column.StringHash()
+		if numeric {
+			if isNumeric(s) {
+				votes--
+			} else {
+				votes++
+			}
+		} else if length >= 0 {
+			if len(s) != length {
+				votes++
+			} else {
+				votes--
+			}
+		}
+	}
+	return votes >= 0
+}
+
+// Guesses the dialect of a delimited sample, such as the start of a file. The delimiter and
+// quote character are chosen so the sample splits into the most consistent number of fields.
+// Ties are broken in favour of commas and double quotes.
+func SniffDialect(sample []byte) (Dialect, error) {
+	if len(bytes.TrimSpace(sample)) == 0 {
+		return Dialect{}, fmt.Errorf("empty sample")
+	}
+
+	lineEnding := "\n"
+	if bytes.Contains(sample, []byte("\r\n")) {
+		lineEnding = "\r\n"
+	}
+
+	// The sample may end in the middle of a record.
+	if last := bytes.LastIndexByte(sample, '\n'); last >= 0 && last < len(sample)-1 {
+		sample = sample[:last+1]
+	}
+
+	res := DialectCsv
+	res.LineEnding = lineEnding
+	bestConsistency := -1.0
+	bestRecords := [][]StringHashable{}
+	for _, delimiter := range sniffDelimiters {
+		for _, quote := range sniffQuotes {
+			dialect := Dialect{Delimiter: delimiter, Quote: quote, LineEnding: lineEnding}
+			records, err := ReadCsvArray(bytes.NewReader(sample), dialect)
+			if err != nil || len(records) == 0 {
+				continue
+			}
+
+			mode, consistency := getFieldCountConsistency(records)
+			if mode <= 1 {
+				continue
+			}
+			if consistency > bestConsistency {
+				bestConsistency = consistency
+				bestRecords = records
+				res = dialect
+			}
+		}
+	}
+
+	if bestConsistency < 0 {
+		bestRecords, _ = ReadCsvArray(bytes.NewReader(sample), res)
+	}
+	res.HasHeader = sniffHeader(bestRecords)
+
+	return res, nil
+}
+
+// For holding the options used when reading csv files.
+type ReadOptions struct {
+	Dialect     *Dialect // The dialect of the file. Use nil to sniff it.
+	Encoding    int      // The character encoding of the file. Use EncodingAuto to detect it.
+	Compression int      // The compression of the file. Use CompressionAuto to detect it from its magic bytes, or else its extension.
+	RaggedRows  int      // How rows with a different number of cells than the first row are fixed, see FixRaggedRows. RaggedRowsFail keeps them as they are.
+}
+
+// For holding a delimited file read by ReadCsvFileResult.
+type CsvFileResult struct {
+	Rows       [][]StringHashable
+	Dialect    Dialect // The dialect used to read the file.
+	RaggedRows []int   // The indices in the file of the ragged rows fixed or skipped according to ReadOptions.RaggedRows.
+}
+
+// Reads a delimited file into a csv array, decompressing it and transcoding it to UTF-8 first.
+// Also returns the dialect used to read it, which was sniffed from the start of the file if
+// none was given in options. Ragged rows are fixed according to options.RaggedRows.
+func ReadCsvFile(name string, options ReadOptions) ([][]StringHashable, Dialect, error) {
+	res, err := ReadCsvFileResult(name, options)
+	if err != nil {
+		return nil, Dialect{}, err
+	}
+	return res.Rows, res.Dialect, nil
+}
+
+// Reads a delimited file like ReadCsvFile, also returning the indices of the ragged
+// rows fixed or skipped according to options.RaggedRows.
+func ReadCsvFileResult(name string, options ReadOptions) (CsvFileResult, error) {
+	err := checkRaggedRows(options.RaggedRows)
+	if err != nil {
+		return CsvFileResult{}, err
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return CsvFileResult{}, err
+	}
+	defer f.Close()
+
+	zr, _, err := newDecompressingFileReader(f, name, options.Compression)
+	if err != nil {
+		return CsvFileResult{}, fmt.Errorf("%s: %w", name, err)
+	}
+	defer zr.Close()
+
+	r, _, err := NewUtf8Reader(zr, options.Encoding)
+	if err != nil {
+		return CsvFileResult{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	br := bufio.NewReaderSize(r, SniffSampleSize)
+	var dialect Dialect
+	if options.Dialect != nil {
+		dialect = *options.Dialect
+	} else {
+		sample, err := br.Peek(SniffSampleSize)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return CsvFileResult{}, err
+		}
+		dialect, err = SniffDialect(sample)
+		if err != nil {
+			return CsvFileResult{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	rows, err := ReadCsvArray(br, dialect)
+	if err != nil {
+		return CsvFileResult{}, fmt.Errorf("%s: %w", name, err)
+	}
+	res := CsvFileResult{Rows: rows, Dialect: dialect, RaggedRows: []int{}}
+	if options.RaggedRows != RaggedRowsFail && len(rows) > 0 {
+		res.Rows, res.RaggedRows, err = FixRaggedRows(rows, options.RaggedRows)
+		if err != nil {
+			return CsvFileResult{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return res, nil
+}
+
+// Writes a csv array to a file using the dialect. The file is compressed according
+// to the extension of its name, such as ".gz" or ".zst".
+func WriteCsvFile(name string, csvArray [][]StringHashable, dialect Dialect) error {
+	err := dialect.CheckAttributes()
+	if err != nil {
+		return err
+	}
+	compression := GetCompressionFromName(name)
+	err = checkWritableCompression(compression)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	zw, err := NewCompressingWriter(f, compression)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = WriteCsvArray(zw, csvArray, dialect)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Parses a delimiter given by name or as a single character, such as "tab", "\t" or ";".
+func ParseDelimiter(s string) (rune, error) {
+	switch strings.ToLower(s) {
+	case "tab", `\t`:
+		return '\t', nil
+	case "comma":
+		return ',', nil
+	case "semicolon":
+		return ';', nil
+	case "pipe":
+		return '|', nil
+	}
+
+	runes := []rune(s)
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("invalid delimiter: %q", s)
+	}
+	return runes[0], nil
+}
@@ -0,0 +1,162 @@
+package csvcheck_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCsvArrayCustomDialect(t *testing.T) {
+	s := "a;b;c\r\n'x;y';'it''s';3\r\n\r\n4;5;'multi\nline'\r\n"
+	dialect := csvcheck.Dialect{Delimiter: ';', Quote: '\''}
+
+	res, err := csvcheck.ReadCsvArray(strings.NewReader(s), dialect)
+
+	expected := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"a", "b", "c"},
+		{"x;y", "it's", "3"},
+		{"4", "5", "multi\nline"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestReadCsvArrayUnterminatedQuote(t *testing.T) {
+	s := "a,b\n1,\"2\n3,4\n"
+
+	_, err := csvcheck.ReadCsvArray(strings.NewReader(s), csvcheck.DialectCsv)
+
+	assert.NotNil(t, err)
+}
+
+func TestReadCsvArrayInvalidDialect(t *testing.T) {
+	dialect := csvcheck.Dialect{Delimiter: '"', Quote: '"'}
+
+	_, err := csvcheck.ReadCsvArray(strings.NewReader("a\n"), dialect)
+
+	assert.NotNil(t, err)
+}
+
+func TestWriteCsvArrayRoundTrip(t *testing.T) {
+	arr := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"a", "b", "c"},
+		{"1|2", "say \"hi\"", " padded"},
+		{"", "multi\nline", "3"},
+	})
+	dialect := csvcheck.DialectPipe
+	dialect.LineEnding = "\r\n"
+
+	var sb strings.Builder
+	err := csvcheck.WriteCsvArray(&sb, arr, dialect)
+	assert.Nil(t, err)
+	assert.Equal(t, "a|b|c\r\n\"1|2\"|\"say \"\"hi\"\"\"|\" padded\"\r\n|\"multi\nline\"|3\r\n", sb.String())
+
+	res, err := csvcheck.ReadCsvArray(strings.NewReader(sb.String()), dialect)
+	assert.Nil(t, err)
+	assert.Equal(t, arr, res)
+}
+
+func TestSniffDialectSemicolonWithDecimalCommas(t *testing.T) {
+	sample := []byte("name;amount;date\r\nfoo;1,5;2024-01-01\r\nbar;2,25;2024-01-02\r\nbaz;3;2024-01")
+
+	dialect, err := csvcheck.SniffDialect(sample)
+
+	assert.Nil(t, err)
+	assert.Equal(t, ';', dialect.Delimiter)
+	assert.Equal(t, '"', dialect.Quote)
+	assert.Equal(t, "\r\n", dialect.LineEnding)
+	assert.True(t, dialect.HasHeader)
+}
+
+func TestSniffDialectTabsAndSingleQuotes(t *testing.T) {
+	sample := []byte("a\tb\n'x\ty'\t1\n'z\tw'\t2\n")
+
+	dialect, err := csvcheck.SniffDialect(sample)
+
+	assert.Nil(t, err)
+	assert.Equal(t, '\t', dialect.Delimiter)
+	assert.Equal(t, '\'', dialect.Quote)
+	assert.Equal(t, "\n", dialect.LineEnding)
+}
+
+func TestSniffDialectNoHeader(t *testing.T) {
+	sample := []byte("1|2|3\n4|5|6\n7|8|9\n")
+
+	dialect, err := csvcheck.SniffDialect(sample)
+
+	assert.Nil(t, err)
+	assert.Equal(t, '|', dialect.Delimiter)
+	assert.False(t, dialect.HasHeader)
+}
+
+func TestSniffDialectEmptySample(t *testing.T) {
+	_, err := csvcheck.SniffDialect([]byte(" \n"))
+
+	assert.NotNil(t, err)
+}
+
+func TestReadCsvFileDifferentDialectsCompare(t *testing.T) {
+	dir := t.TempDir()
+	name1 := filepath.Join(dir, "left.csv")
+	name2 := filepath.Join(dir, "right.csv")
+	assert.Nil(t, os.WriteFile(name1, []byte("a,b\n1,2\n3,4\n"), 0o644))
+	assert.Nil(t, os.WriteFile(name2, []byte("b;a\r\n2;1\r\n5;3\r\n"), 0o644))
+
+	arr1, dialect1, err := csvcheck.ReadCsvFile(name1, csvcheck.ReadOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, ',', dialect1.Delimiter)
+	arr2, dialect2, err := csvcheck.ReadCsvFile(name2, csvcheck.ReadOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, ';', dialect2.Delimiter)
+
+	_, _, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, csvcheck.Options{SortIndices: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2}, indices1)
+	assert.Equal(t, []int{0, 2}, indices2)
+}
+
+func TestWriteCsvFileRoundTrip(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "out.tsv")
+	arr := getCsvArray1()
+
+	err := csvcheck.WriteCsvFile(name, arr, csvcheck.DialectTsv)
+	assert.Nil(t, err)
+
+	res, dialect, err := csvcheck.ReadCsvFile(name, csvcheck.ReadOptions{Dialect: &csvcheck.DialectTsv})
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.DialectTsv, dialect)
+	assert.Equal(t, arr, res)
+}
+
+func TestWriteCsvFileInvalidDialectKeepsFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "out.csv")
+	assert.Nil(t, os.WriteFile(name, []byte("a,b\n1,2\n"), 0o644))
+
+	err := csvcheck.WriteCsvFile(name, getCsvArray1(), csvcheck.Dialect{Delimiter: '"', Quote: '"'})
+	assert.NotNil(t, err)
+	content, err := os.ReadFile(name)
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b\n1,2\n", string(content))
+
+	name = filepath.Join(t.TempDir(), "new.csv")
+	err = csvcheck.WriteCsvFile(name, getCsvArray1(), csvcheck.Dialect{})
+	assert.NotNil(t, err)
+	_, err = os.Stat(name)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestParseDelimiter(t *testing.T) {
+	for s, expected := range map[string]rune{"tab": '\t', `\t`: '\t', ";": ';', "pipe": '|', ",": ','} {
+		d, err := csvcheck.ParseDelimiter(s)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, d)
+	}
+
+	_, err := csvcheck.ParseDelimiter(";;")
+	assert.NotNil(t, err)
+}
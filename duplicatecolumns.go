@@ -0,0 +1,96 @@
+package csvcheck
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported ways of handling duplicate column names.
+const (
+	DuplicateColumnsFail = iota
+	DuplicateColumnsSuffix
+	DuplicateColumnsPosition
+)
+
+// Separates a duplicate column name from its disambiguating suffix.
+const DuplicateColumnSeparator = "_"
+
+// Returns the way of handling duplicate column names with the given name.
+func ParseDuplicateColumns(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "", "fail":
+		return DuplicateColumnsFail, nil
+	case "suffix":
+		return DuplicateColumnsSuffix, nil
+	case "position":
+		return DuplicateColumnsPosition, nil
+	}
+	return 0, &InvalidOptionError{Option: "duplicate columns mode", Value: s}
+}
+
+// Returns a copy of the header row with duplicate and empty column names made unique.
+// Empty names are replaced by the synthetic name of their position, such as "col3".
+// With DuplicateColumnsSuffix, repeated names get a suffix counting their occurrences,
+// such as "Notes", "Notes_2". With DuplicateColumnsPosition, every occurrence of a
+// repeated name gets the synthetic name of its position as a suffix, such as
+// "Notes_col4". Names already taken are avoided by adding further suffixes.
+// With DuplicateColumnsFail, the header row is returned as is.
+func DisambiguateHeader(header []StringHashable, mode int) []StringHashable {
+	res := make([]StringHashable, len(header))
+	copy(res, header)
+	if mode == DuplicateColumnsFail {
+		return res
+	}
+
+	counts := make(map[string]int)
+	for _, column := range header {
+		counts[column.StringHash()]++
+	}
+	taken := make(map[string]bool)
+	for name, count := range counts {
+		if count == 1 && name != "" {
+			taken[name] = true
+		}
+	}
+
+	occurrences := make(map[string]int)
+	for i, column := range header {
+		name := column.StringHash()
+		occurrences[name]++
+		if counts[name] == 1 && name != "" {
+			continue
+		}
+
+		var newName string
+		switch {
+		case name == "":
+			newName = ColumnIndex(i).StringHash()
+		case mode == DuplicateColumnsPosition:
+			newName = name + DuplicateColumnSeparator + ColumnIndex(i).StringHash()
+		case occurrences[name] == 1:
+			newName = name
+		default:
+			newName = fmt.Sprintf("%s%s%d", name, DuplicateColumnSeparator, occurrences[name])
+		}
+
+		unique := newName
+		for n := 2; taken[unique]; n++ {
+			unique = fmt.Sprintf("%s%s%d", newName, DuplicateColumnSeparator, n)
+		}
+		taken[unique] = true
+		res[i] = BasicStringHashable(unique)
+	}
+	return res
+}
+
+// Returns a shallow copy of the array with its header row disambiguated by DisambiguateHeader.
+func disambiguateColumns(arr [][]StringHashable, mode int) [][]StringHashable {
+	if mode == DuplicateColumnsFail || len(arr) == 0 {
+		return arr
+	}
+
+	res := make([][]StringHashable, len(arr))
+	copy(res, arr)
+	res[0] = DisambiguateHeader(arr[0], mode)
+	return res
+}
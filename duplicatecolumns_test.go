@@ -0,0 +1,81 @@
+package csvcheck_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisambiguateHeaderSuffix(t *testing.T) {
+	header := csvcheck.GetRowFromRow([]string{"Notes", "a", "Notes", "", "Notes_2", "", "Notes"})
+
+	res := csvcheck.DisambiguateHeader(header, csvcheck.DuplicateColumnsSuffix)
+
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"Notes", "a", "Notes_2_2", "col4", "Notes_2", "col6", "Notes_3"}), res)
+	assert.Equal(t, "Notes", header[2].StringHash())
+}
+
+func TestDisambiguateHeaderPosition(t *testing.T) {
+	header := csvcheck.GetRowFromRow([]string{"Notes", "a", "Notes", "", "col4"})
+
+	res := csvcheck.DisambiguateHeader(header, csvcheck.DuplicateColumnsPosition)
+
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"Notes_col1", "a", "Notes_col3", "col4_2", "col4"}), res)
+}
+
+func TestDisambiguateHeaderFail(t *testing.T) {
+	header := csvcheck.GetRowFromRow([]string{"Notes", "Notes"})
+
+	assert.Equal(t, header, csvcheck.DisambiguateHeader(header, csvcheck.DuplicateColumnsFail))
+}
+
+func TestGetDifferentRowsDuplicateColumns(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"Notes", "a", "Notes"},
+		{"x", "1", "y"},
+		{"z", "2", "w"},
+	})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"Notes", "Notes", "a"},
+		{"x", "y", "1"},
+		{"w", "z", "2"},
+	})
+	options := csvcheck.Options{DuplicateColumns: csvcheck.DuplicateColumnsSuffix, SortIndices: true}
+
+	res1, res2, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2}, indices1)
+	assert.Equal(t, []int{0, 2}, indices2)
+	assert.Equal(t, arr1[0], res1[0])
+	assert.Equal(t, arr2[0], res2[0])
+
+	options.IgnoreColumns = csvcheck.GetRowFromRow([]string{"Notes", "Notes_2"})
+	_, _, indices1, _, err = csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0}, indices1)
+}
+
+func TestGetCommonRowsDuplicateColumnsFail(t *testing.T) {
+	arr := getImproperCsvArrayDifferingRepeatedColumnNames()
+
+	_, _, _, _, err := csvcheck.GetCommonRows(arr, arr, csvcheck.Options{})
+
+	var duplicateErr *csvcheck.DuplicateColumnError
+	assert.True(t, errors.As(err, &duplicateErr))
+
+	_, _, _, _, err = csvcheck.GetCommonRows(arr, arr, csvcheck.Options{DuplicateColumns: csvcheck.DuplicateColumnsPosition})
+	assert.Nil(t, err)
+}
+
+func TestParseDuplicateColumns(t *testing.T) {
+	mode, err := csvcheck.ParseDuplicateColumns("Suffix")
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.DuplicateColumnsSuffix, mode)
+
+	_, err = csvcheck.ParseDuplicateColumns("rename")
+	assert.NotNil(t, err)
+}
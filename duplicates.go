@@ -0,0 +1,126 @@
+package csvcheck
+
+import "fmt"
+
+// Supported ways of choosing which row of a group of duplicate rows is kept by DropDuplicateRows.
+const (
+	DuplicateRowsKeepFirst = iota
+	DuplicateRowsKeepLast
+)
+
+// Returns the groups of indices of the rows of the array that share the same values
+// in the compared columns, or in options.KeyColumns if given. The array is prepared
+// like the first array of a comparison: ragged rows are handled by options.RaggedRows,
+// only the rows matching options.Where are considered, and options.DerivedColumns1,
+// options.DateColumns and options.UnorderedColumns apply. The indices of each group
+// are in increasing order, and the groups are in the order of their first rows. Rows
+// without a duplicate are not included.
+func FindDuplicateRows(csvArray [][]StringHashable, options Options) ([][]int, error) {
+	return findDuplicateRows(csvArray, options, false)
+}
+
+// Returns the groups of indices of the duplicate rows of the array like FindDuplicateRows,
+// preparing it like the second array of a comparison instead: options.DerivedColumns2
+// apply, and the columns paired by options.ColumnMapping are named by their left names.
+func FindDuplicateRightRows(csvArray [][]StringHashable, options Options) ([][]int, error) {
+	return findDuplicateRows(csvArray, options, true)
+}
+
+// Helper function for finding the duplicate rows of the first array of a comparison,
+// or of the second if right is set.
+func findDuplicateRows(csvArray [][]StringHashable, options Options, right bool) ([][]int, error) {
+	err := options.CheckAttributes()
+	if err != nil {
+		return nil, err
+	}
+	derived := options.DerivedColumns1
+	if right {
+		derived = options.DerivedColumns2
+	}
+	compared, arr, err := getComparedArray(csvArray, derived, options)
+	if err != nil {
+		return nil, err
+	}
+	if right {
+		arr, _, err = renameMappedColumns(arr, options.ColumnMapping)
+		if err != nil {
+			return nil, err
+		}
+	}
+	arr, err = compared.prepareRows(arr, options)
+	if err != nil {
+		return nil, err
+	}
+
+	header := arr[0]
+	columns := options.KeyColumns
+	if columns != nil {
+		columns, err = resolveColumnIndices(header, columns)
+	} else {
+		columns, err = getComparedColumns(header, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, ErrNoColumnsToCompare
+	}
+	indices, err := getColumnIndices(header, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]rowKey, len(arr)-1)
+	for i, row := range arr[1:] {
+		keys[i] = getKeyOfRow(row, indices)
+	}
+	mapping := getRowsMapping(keys)
+
+	res := [][]int{}
+	for i, key := range keys {
+		group := mapping[key]
+		if len(group) < 2 || group[0] != i {
+			continue
+		}
+		original := make([]int, len(group))
+		for j, index := range group {
+			original[j] = compared.indices[index]
+		}
+		res = append(res, original)
+	}
+	return res, nil
+}
+
+// Returns a copy of the array without the duplicate rows found by FindDuplicateRows,
+// keeping the first or the last row of each group according to keep, such as
+// DuplicateRowsKeepFirst. Other rows, including the header, are kept as they are.
+func DropDuplicateRows(csvArray [][]StringHashable, options Options, keep int) ([][]StringHashable, error) {
+	if keep != DuplicateRowsKeepFirst && keep != DuplicateRowsKeepLast {
+		return nil, &InvalidOptionError{Option: "duplicate rows mode", Value: fmt.Sprint(keep)}
+	}
+	groups, err := FindDuplicateRows(csvArray, options)
+	if err != nil {
+		return nil, err
+	}
+
+	dropped := make(map[int]bool)
+	for _, group := range groups {
+		kept := group[0]
+		if keep == DuplicateRowsKeepLast {
+			kept = group[len(group)-1]
+		}
+		for _, index := range group {
+			if index != kept {
+				dropped[index] = true
+			}
+		}
+	}
+
+	res := make([][]StringHashable, 0, len(csvArray)-len(dropped))
+	for i, row := range csvArray {
+		if !dropped[i] {
+			res = append(res, row)
+		}
+	}
+	return res, nil
+}
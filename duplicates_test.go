@@ -0,0 +1,105 @@
+package csvcheck_test
+
+import (
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getDuplicatesCsvArray() [][]csvcheck.StringHashable {
+	return csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "name", "amount"},
+		{"1", "a", "10"},
+		{"2", "b", "20"},
+		{"1", "a", "10"},
+		{"2", "b", "25"},
+		{"3", "c", "30"},
+		{"1", "a", "10"},
+	})
+}
+
+func TestFindDuplicateRows(t *testing.T) {
+	arr := getDuplicatesCsvArray()
+
+	groups, err := csvcheck.FindDuplicateRows(arr, csvcheck.Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{1, 3, 6}}, groups)
+
+	groups, err = csvcheck.FindDuplicateRows(arr, csvcheck.Options{KeyColumns: csvcheck.GetRowFromRow([]string{"id"})})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{1, 3, 6}, {2, 4}}, groups)
+
+	groups, err = csvcheck.FindDuplicateRows(arr, csvcheck.Options{IgnoreColumns: csvcheck.GetRowFromRow([]string{"amount"})})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{1, 3, 6}, {2, 4}}, groups)
+
+	groups, err = csvcheck.FindDuplicateRows(arr, csvcheck.Options{Where: "amount < 20"})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{1, 3, 6}}, groups)
+
+	groups, err = csvcheck.FindDuplicateRows(getCsvArray1(), csvcheck.Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{}, groups)
+}
+
+func TestFindDuplicateRowsNoHeader(t *testing.T) {
+	arr := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"1", "a"},
+		{"2", "b"},
+		{"1", "a"},
+	})
+
+	groups, err := csvcheck.FindDuplicateRows(arr, csvcheck.Options{NoHeader: true})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{0, 2}}, groups)
+}
+
+func TestFindDuplicateRowsMissingKeyColumn(t *testing.T) {
+	_, err := csvcheck.FindDuplicateRows(getDuplicatesCsvArray(), csvcheck.Options{KeyColumns: csvcheck.GetRowFromRow([]string{"code"})})
+	var notFound *csvcheck.ColumnNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestFindDuplicateRightRows(t *testing.T) {
+	arr := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"CustomerID", "name"},
+		{"1", "a"},
+		{"2", "b"},
+		{"1", "c"},
+	})
+	options := csvcheck.Options{
+		ColumnMapping: map[string]string{"cust_id": "CustomerID"},
+		KeyColumns:    csvcheck.GetRowFromRow([]string{"cust_id"}),
+	}
+
+	groups, err := csvcheck.FindDuplicateRightRows(arr, options)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{1, 3}}, groups)
+
+	options.Where = "cust_id == 1"
+	groups, err = csvcheck.FindDuplicateRightRows(arr, options)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{1, 3}}, groups)
+
+	_, err = csvcheck.FindDuplicateRows(arr, options)
+	var notFound *csvcheck.ColumnNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestDropDuplicateRows(t *testing.T) {
+	arr := getDuplicatesCsvArray()
+	options := csvcheck.Options{KeyColumns: csvcheck.GetRowFromRow([]string{"id"})}
+
+	res, err := csvcheck.DropDuplicateRows(arr, options, csvcheck.DuplicateRowsKeepFirst)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]csvcheck.StringHashable{arr[0], arr[1], arr[2], arr[5]}, res)
+
+	res, err = csvcheck.DropDuplicateRows(arr, options, csvcheck.DuplicateRowsKeepLast)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]csvcheck.StringHashable{arr[0], arr[4], arr[5], arr[6]}, res)
+
+	_, err = csvcheck.DropDuplicateRows(arr, options, 5)
+	assert.NotNil(t, err)
+}
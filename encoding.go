@@ -0,0 +1,234 @@
+package csvcheck
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Supported character encodings.
+const (
+	EncodingAuto = iota
+	EncodingUtf8
+	EncodingUtf16LE
+	EncodingUtf16BE
+	EncodingWindows1252
+	EncodingLatin1
+)
+
+// Byte order marks recognized when detecting the encoding.
+var (
+	bomUtf8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUtf16LE = []byte{0xFF, 0xFE}
+	bomUtf16BE = []byte{0xFE, 0xFF}
+)
+
+// Written in place of undecodable input. It is never valid in UTF-8, so
+// ReadCsvArray reports it with its position.
+const invalidByte = 0xFF
+
+// The characters of Windows-1252 that differ from Latin-1. Zero marks undefined bytes.
+var windows1252Table = [32]rune{
+	0x20AC, 0, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017D, 0,
+	0, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0, 0x017E, 0x0178,
+}
+
+// Returns the encoding with the given name.
+func ParseEncoding(s string) (int, error) {
+	switch strings.ToLower(strings.ReplaceAll(s, "_", "-")) {
+	case "", "auto":
+		return EncodingAuto, nil
+	case "utf-8", "utf8":
+		return EncodingUtf8, nil
+	case "utf-16le", "utf16le", "utf-16", "utf16":
+		return EncodingUtf16LE, nil
+	case "utf-16be", "utf16be":
+		return EncodingUtf16BE, nil
+	case "windows-1252", "cp1252":
+		return EncodingWindows1252, nil
+	case "latin-1", "latin1", "iso-8859-1":
+		return EncodingLatin1, nil
+	}
+	return 0, &InvalidOptionError{Option: "encoding", Value: s}
+}
+
+// Returns the length of the byte order mark at the start of sample
+// for the encoding, or 0 if there is none.
+func getBomLength(sample []byte, encoding int) int {
+	switch {
+	case encoding == EncodingUtf8 && bytes.HasPrefix(sample, bomUtf8):
+		return len(bomUtf8)
+	case encoding == EncodingUtf16LE && bytes.HasPrefix(sample, bomUtf16LE):
+		return len(bomUtf16LE)
+	case encoding == EncodingUtf16BE && bytes.HasPrefix(sample, bomUtf16BE):
+		return len(bomUtf16BE)
+	}
+	return 0
+}
+
+// Guesses the encoding of a sample, such as the start of a file. Byte order marks
+// are trusted first. Otherwise UTF-16 is recognized by its zero bytes, valid UTF-8
+// is taken as is and anything else is assumed to be Windows-1252.
+func DetectEncoding(sample []byte) int {
+	switch {
+	case bytes.HasPrefix(sample, bomUtf8):
+		return EncodingUtf8
+	case bytes.HasPrefix(sample, bomUtf16LE):
+		return EncodingUtf16LE
+	case bytes.HasPrefix(sample, bomUtf16BE):
+		return EncodingUtf16BE
+	}
+
+	evenZeros, oddZeros := 0, 0
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	half := len(sample) / 2
+	if half > 0 && oddZeros > half/4 && evenZeros < oddZeros/4 {
+		return EncodingUtf16LE
+	}
+	if half > 0 && evenZeros > half/4 && oddZeros < evenZeros/4 {
+		return EncodingUtf16BE
+	}
+
+	// The sample may end in the middle of a character.
+	end := len(sample)
+	for i := 1; i < utf8.UTFMax && i <= len(sample); i++ {
+		if utf8.RuneStart(sample[len(sample)-i]) {
+			if !utf8.FullRune(sample[len(sample)-i:]) {
+				end = len(sample) - i
+			}
+			break
+		}
+	}
+	if utf8.Valid(sample[:end]) {
+		return EncodingUtf8
+	}
+	return EncodingWindows1252
+}
+
+// Returns the next character of a single byte encoded stream.
+func decodeSingleByte(r *bufio.Reader, encoding int) (rune, bool, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, false, err
+	}
+	if encoding == EncodingWindows1252 && b >= 0x80 && b < 0xA0 {
+		c := windows1252Table[b-0x80]
+		return c, c != 0, nil
+	}
+	return rune(b), true, nil
+}
+
+// Returns the next character of a UTF-16 encoded stream.
+func decodeUtf16(r *bufio.Reader, encoding int) (rune, bool, error) {
+	unit := func(b []byte) rune {
+		if encoding == EncodingUtf16LE {
+			return rune(b[0]) | rune(b[1])<<8
+		}
+		return rune(b[0])<<8 | rune(b[1])
+	}
+
+	b, err := r.Peek(2)
+	if len(b) < 2 {
+		if len(b) == 1 {
+			r.Discard(1)
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	r.Discard(2)
+
+	c := unit(b)
+	if !utf16.IsSurrogate(c) {
+		return c, true, nil
+	}
+	if c >= 0xDC00 {
+		return 0, false, nil
+	}
+
+	b, _ = r.Peek(2)
+	if len(b) < 2 {
+		return 0, false, nil
+	}
+	c = utf16.DecodeRune(c, unit(b))
+	if c == utf8.RuneError {
+		return 0, false, nil
+	}
+	r.Discard(2)
+	return c, true, nil
+}
+
+// An io.Reader that transcodes its source to UTF-8.
+type decodingReader struct {
+	r        *bufio.Reader
+	encoding int
+	buf      []byte
+	err      error
+}
+
+func (d *decodingReader) Read(p []byte) (int, error) {
+	for len(d.buf) < len(p) && d.err == nil {
+		var c rune
+		var valid bool
+		if d.encoding == EncodingUtf16LE || d.encoding == EncodingUtf16BE {
+			c, valid, d.err = decodeUtf16(d.r, d.encoding)
+		} else {
+			c, valid, d.err = decodeSingleByte(d.r, d.encoding)
+		}
+		if d.err != nil {
+			break
+		}
+
+		if valid {
+			d.buf = utf8.AppendRune(d.buf, c)
+		} else {
+			d.buf = append(d.buf, invalidByte)
+		}
+	}
+
+	if len(d.buf) == 0 {
+		return 0, d.err
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// Returns a reader that transcodes r from the given encoding to UTF-8, along with the
+// encoding used, which is detected from the start of r for EncodingAuto. Byte order
+// marks are removed. Undecodable input is passed on as invalid UTF-8 so that
+// ReadCsvArray can report where it is.
+func NewUtf8Reader(r io.Reader, encoding int) (io.Reader, int, error) {
+	br := bufio.NewReaderSize(r, SniffSampleSize)
+	sample, err := br.Peek(SniffSampleSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, 0, err
+	}
+
+	if encoding == EncodingAuto {
+		encoding = DetectEncoding(sample)
+	}
+	br.Discard(getBomLength(sample, encoding))
+
+	switch encoding {
+	case EncodingUtf8:
+		return br, encoding, nil
+	case EncodingUtf16LE, EncodingUtf16BE, EncodingWindows1252, EncodingLatin1:
+		return &decodingReader{r: br, encoding: encoding}, encoding, nil
+	}
+	return nil, 0, &InvalidOptionError{Option: "encoding", Value: fmt.Sprint(encoding)}
+}
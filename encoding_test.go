@@ -0,0 +1,137 @@
+package csvcheck_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeUtf16LE(s string, bom bool) []byte {
+	res := []byte{}
+	if bom {
+		res = append(res, 0xFF, 0xFE)
+	}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		res = append(res, byte(unit), byte(unit>>8))
+	}
+	return res
+}
+
+func TestDetectEncoding(t *testing.T) {
+	assert.Equal(t, csvcheck.EncodingUtf8, csvcheck.DetectEncoding([]byte("\xEF\xBB\xBFa,b\n")))
+	assert.Equal(t, csvcheck.EncodingUtf8, csvcheck.DetectEncoding([]byte("name\ncafé\n")))
+	assert.Equal(t, csvcheck.EncodingUtf8, csvcheck.DetectEncoding([]byte("name\ncaf\xC3")))
+	assert.Equal(t, csvcheck.EncodingUtf16LE, csvcheck.DetectEncoding(encodeUtf16LE("a,b\n", true)))
+	assert.Equal(t, csvcheck.EncodingUtf16LE, csvcheck.DetectEncoding(encodeUtf16LE("a,b\n1,2\n", false)))
+	assert.Equal(t, csvcheck.EncodingUtf16BE, csvcheck.DetectEncoding([]byte{0xFE, 0xFF, 0, 'a'}))
+	assert.Equal(t, csvcheck.EncodingWindows1252, csvcheck.DetectEncoding([]byte("name\ncaf\xE9\n")))
+}
+
+func TestNewUtf8ReaderWindows1252(t *testing.T) {
+	r, encoding, err := csvcheck.NewUtf8Reader(bytes.NewReader([]byte("caf\xE9 \x80\x96\n")), csvcheck.EncodingAuto)
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.EncodingWindows1252, encoding)
+
+	res, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "café €–\n", string(res))
+}
+
+func TestNewUtf8ReaderLatin1(t *testing.T) {
+	r, _, err := csvcheck.NewUtf8Reader(bytes.NewReader([]byte("caf\xE9\x80")), csvcheck.EncodingLatin1)
+	assert.Nil(t, err)
+
+	res, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "café\u0080", string(res))
+}
+
+func TestNewUtf8ReaderUtf16LEWithBom(t *testing.T) {
+	r, encoding, err := csvcheck.NewUtf8Reader(bytes.NewReader(encodeUtf16LE("a,b\nnaïve,😀\n", true)), csvcheck.EncodingAuto)
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.EncodingUtf16LE, encoding)
+
+	res, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b\nnaïve,😀\n", string(res))
+}
+
+func TestNewUtf8ReaderStripsUtf8Bom(t *testing.T) {
+	r, _, err := csvcheck.NewUtf8Reader(strings.NewReader("\xEF\xBB\xBFa,b\n"), csvcheck.EncodingAuto)
+	assert.Nil(t, err)
+
+	res, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b\n", string(res))
+}
+
+func TestReadCsvArrayReportsInvalidByteSequencePosition(t *testing.T) {
+	s := "a,b,c\n1,2,3\n4,5,caf\xE9\n"
+
+	_, err := csvcheck.ReadCsvArray(strings.NewReader(s), csvcheck.DialectCsv)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "row 2 column 2")
+}
+
+func TestReadCsvFileExplicitEncodingReportsInvalidByteSequencePosition(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "unpaired.csv")
+	data := encodeUtf16LE("a,b\n1,", false)
+	data = append(data, 0x00, 0xDC)
+	data = append(data, encodeUtf16LE("\n", false)...)
+	assert.Nil(t, os.WriteFile(name, data, 0o644))
+
+	_, _, err := csvcheck.ReadCsvFile(name, csvcheck.ReadOptions{
+		Dialect:  &csvcheck.DialectCsv,
+		Encoding: csvcheck.EncodingUtf16LE,
+	})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "row 1 column 1")
+}
+
+func TestReadCsvFileDifferentEncodingsCompare(t *testing.T) {
+	dir := t.TempDir()
+	name1 := filepath.Join(dir, "legacy.csv")
+	name2 := filepath.Join(dir, "utf16.csv")
+	name3 := filepath.Join(dir, "export.csv")
+	assert.Nil(t, os.WriteFile(name1, []byte("name,city\nRen\xE9e,Z\xFCrich\nJos\xE9,M\xE1laga\n"), 0o644))
+	assert.Nil(t, os.WriteFile(name2, encodeUtf16LE("name,city\r\nRenée,Zürich\r\nJosé,Málaga\r\n", true), 0o644))
+	assert.Nil(t, os.WriteFile(name3, []byte("\xEF\xBB\xBFname,city\nRenée,Zürich\nJosé,Málaga\n"), 0o644))
+
+	arr1, _, err := csvcheck.ReadCsvFile(name1, csvcheck.ReadOptions{})
+	assert.Nil(t, err)
+	arr2, _, err := csvcheck.ReadCsvFile(name2, csvcheck.ReadOptions{})
+	assert.Nil(t, err)
+	arr3, _, err := csvcheck.ReadCsvFile(name3, csvcheck.ReadOptions{})
+	assert.Nil(t, err)
+
+	assert.Equal(t, arr3, arr1)
+	assert.Equal(t, arr3, arr2)
+}
+
+func TestParseEncoding(t *testing.T) {
+	for s, expected := range map[string]int{
+		"auto":         csvcheck.EncodingAuto,
+		"UTF-8":        csvcheck.EncodingUtf8,
+		"utf-16le":     csvcheck.EncodingUtf16LE,
+		"UTF_16BE":     csvcheck.EncodingUtf16BE,
+		"windows-1252": csvcheck.EncodingWindows1252,
+		"latin1":       csvcheck.EncodingLatin1,
+	} {
+		encoding, err := csvcheck.ParseEncoding(s)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, encoding)
+	}
+
+	_, err := csvcheck.ParseEncoding("ebcdic")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,133 @@
+package csvcheck
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned when validating csv arrays and options.
+var (
+	ErrEmptyArray         = errors.New("empty array")
+	ErrNoColumnsToCompare = errors.New("no columns to compare")
+	ErrConflictingColumns = errors.New("cannot use both UseColumns and IgnoreColumns together")
+)
+
+// For reporting a column name that appears more than once in a header row.
+type DuplicateColumnError struct {
+	Name string
+}
+
+func (e *DuplicateColumnError) Error() string {
+	return fmt.Sprintf("duplicate column: %s", e.Name)
+}
+
+// For reporting a row that does not have as many columns as the header row.
+type RaggedRowError struct {
+	Row  int // The index of the row in the csv array.
+	Got  int
+	Want int
+}
+
+func (e *RaggedRowError) Error() string {
+	return fmt.Sprintf("row %d has %d columns, expected %d", e.Row, e.Got, e.Want)
+}
+
+// For reporting columns that are not shared by both sides of a comparison.
+type ColumnMismatchError struct {
+	OnlyLeft    []StringHashable
+	OnlyRight   []StringHashable
+	Suggestions []ColumnSuggestion // Pairs of columns that were likely meant to match.
+}
+
+func (e *ColumnMismatchError) Error() string {
+	details := []string{}
+	if len(e.OnlyLeft) > 0 {
+		details = append(details, "only in left: "+strings.Join(getStringsRow(e.OnlyLeft), ", "))
+	}
+	if len(e.OnlyRight) > 0 {
+		details = append(details, "only in right: "+strings.Join(getStringsRow(e.OnlyRight), ", "))
+	}
+	if len(e.Suggestions) > 0 {
+		suggestions := make([]string, len(e.Suggestions))
+		for i, suggestion := range e.Suggestions {
+			suggestions[i] = suggestion.String()
+		}
+		details = append(details, "possible matches: "+strings.Join(suggestions, ", "))
+	}
+	if len(details) == 0 {
+		return "check the columns being compared"
+	}
+	return fmt.Sprintf("check the columns being compared (%s)", strings.Join(details, "; "))
+}
+
+// For reporting a column that is missing from a header row.
+type ColumnNotFoundError struct {
+	Name string
+}
+
+func (e *ColumnNotFoundError) Error() string {
+	return fmt.Sprintf("column %s not found", e.Name)
+}
+
+// For reporting a comparison method that is not one of the supported ones.
+type UnsupportedMethodError struct {
+	Method int
+}
+
+func (e *UnsupportedMethodError) Error() string {
+	return fmt.Sprintf("unsupported method: %d", e.Method)
+}
+
+// For reporting an option that is set to an unsupported value, such as an unknown
+// ragged rows mode, or to an invalid one, such as a column mapping pairing two
+// columns with the same column.
+type InvalidOptionError struct {
+	Option string // The name of the option, such as "ragged rows mode".
+	Value  string
+	Reason string // Why the value is invalid, or empty if it is simply not supported.
+}
+
+func (e *InvalidOptionError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("invalid %s: %s", e.Option, e.Reason)
+	}
+	return fmt.Sprintf("unsupported %s: %s", e.Option, e.Value)
+}
+
+// Returns the columns of row1 missing from row2 and the columns of row2 missing
+// from row1, keeping the order of the rows.
+func getColumnsOnlyInEither(row1, row2 []StringHashable) ([]StringHashable, []StringHashable) {
+	marker1 := make(map[uint64]bool)
+	for _, column := range row1 {
+		marker1[getStringKey(column)] = true
+	}
+	marker2 := make(map[uint64]bool)
+	for _, column := range row2 {
+		marker2[getStringKey(column)] = true
+	}
+
+	onlyLeft := []StringHashable{}
+	for _, column := range row1 {
+		if !marker2[getStringKey(column)] {
+			onlyLeft = append(onlyLeft, column)
+		}
+	}
+	onlyRight := []StringHashable{}
+	for _, column := range row2 {
+		if !marker1[getStringKey(column)] {
+			onlyRight = append(onlyRight, column)
+		}
+	}
+	return onlyLeft, onlyRight
+}
+
+// Returns an error describing the columns of row1 and row2 that do not match up.
+func newColumnMismatchError(row1, row2 []StringHashable) *ColumnMismatchError {
+	onlyLeft, onlyRight := getColumnsOnlyInEither(row1, row2)
+	return &ColumnMismatchError{
+		OnlyLeft:    onlyLeft,
+		OnlyRight:   onlyRight,
+		Suggestions: getColumnSuggestions(onlyLeft, onlyRight),
+	}
+}
@@ -0,0 +1,115 @@
+package csvcheck_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckForProperCsvArrayEmptyArrayError(t *testing.T) {
+	err := csvcheck.CheckForProperCsvArray(getEmpty2DArray())
+
+	assert.True(t, errors.Is(err, csvcheck.ErrEmptyArray))
+}
+
+func TestCheckForProperCsvArrayDuplicateColumnError(t *testing.T) {
+	err := csvcheck.CheckForProperCsvArray(getImproperCsvArrayDifferingRepeatedColumnNames())
+
+	var duplicateErr *csvcheck.DuplicateColumnError
+	assert.True(t, errors.As(err, &duplicateErr))
+	assert.Equal(t, "c", duplicateErr.Name)
+	assert.Equal(t, "duplicate column: c", err.Error())
+}
+
+func TestCheckForProperCsvArrayRaggedRowError(t *testing.T) {
+	arr := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"a", "b", "c"},
+		{"1", "2", "3"},
+		{"4", "5"},
+	})
+
+	err := csvcheck.CheckForProperCsvArray(arr)
+
+	var raggedErr *csvcheck.RaggedRowError
+	assert.True(t, errors.As(err, &raggedErr))
+	assert.Equal(t, csvcheck.RaggedRowError{Row: 2, Got: 2, Want: 3}, *raggedErr)
+	assert.Equal(t, "row 2 has 2 columns, expected 3", err.Error())
+}
+
+func TestGetCommonRowsColumnMismatchError(t *testing.T) {
+	_, _, _, _, err := csvcheck.GetCommonRows(getCsvArray1(), getCsvArray3(), csvcheck.Options{})
+
+	var mismatchErr *csvcheck.ColumnMismatchError
+	assert.True(t, errors.As(err, &mismatchErr))
+	assert.Empty(t, mismatchErr.OnlyLeft)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"d"}), mismatchErr.OnlyRight)
+	assert.Equal(t, "check the columns being compared (only in right: d)", err.Error())
+}
+
+func TestGetDifferentRowsNoColumnsToCompareError(t *testing.T) {
+	options := csvcheck.Options{UseColumns: csvcheck.GetRowFromRow([]string{"x"})}
+
+	_, _, _, _, err := csvcheck.GetDifferentRows(getCsvArray1(), getCsvArray2(), options)
+
+	assert.True(t, errors.Is(err, csvcheck.ErrNoColumnsToCompare))
+}
+
+func TestRearrangeColumnsColumnMismatchError(t *testing.T) {
+	_, err := csvcheck.RearrangeColumns(getCsvArray1(), csvcheck.GetRowFromRow([]string{"c", "a", "x"}))
+
+	var mismatchErr *csvcheck.ColumnMismatchError
+	assert.True(t, errors.As(err, &mismatchErr))
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"b"}), mismatchErr.OnlyLeft)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"x"}), mismatchErr.OnlyRight)
+}
+
+func TestRearrangeColumnsDuplicateColumnError(t *testing.T) {
+	_, err := csvcheck.RearrangeColumns(getCsvArray1(), csvcheck.GetRowFromRow([]string{"c", "a", "b", "a"}))
+
+	var duplicateErr *csvcheck.DuplicateColumnError
+	assert.True(t, errors.As(err, &duplicateErr))
+	assert.Equal(t, "a", duplicateErr.Name)
+}
+
+func TestOptionsCheckAttributesErrors(t *testing.T) {
+	options := csvcheck.Options{Method: 100}
+	err := options.CheckAttributes()
+
+	var methodErr *csvcheck.UnsupportedMethodError
+	assert.True(t, errors.As(err, &methodErr))
+	assert.Equal(t, 100, methodErr.Method)
+
+	options = csvcheck.Options{
+		UseColumns:    csvcheck.GetRowFromRow([]string{"a"}),
+		IgnoreColumns: csvcheck.GetRowFromRow([]string{"b"}),
+	}
+	assert.True(t, errors.Is(options.CheckAttributes(), csvcheck.ErrConflictingColumns))
+}
+
+func TestInvalidOptionError(t *testing.T) {
+	var optionErr *csvcheck.InvalidOptionError
+
+	_, err := csvcheck.ParseRaggedRows("stretch")
+	assert.True(t, errors.As(err, &optionErr))
+	assert.Equal(t, "ragged rows mode", optionErr.Option)
+	assert.Equal(t, "stretch", optionErr.Value)
+	assert.Equal(t, "unsupported ragged rows mode: stretch", err.Error())
+
+	options := csvcheck.Options{DuplicateColumns: 100}
+	assert.True(t, errors.As(options.CheckAttributes(), &optionErr))
+	assert.Equal(t, "duplicate columns mode", optionErr.Option)
+	assert.Equal(t, "100", optionErr.Value)
+
+	options = csvcheck.Options{ColumnMapping: map[string]string{"a": "c", "b": "c"}}
+	err = options.CheckAttributes()
+	assert.True(t, errors.As(err, &optionErr))
+	assert.Equal(t, "column mapping", optionErr.Option)
+	assert.Equal(t, "invalid column mapping: columns a and b are both mapped to c", err.Error())
+
+	_, err = csvcheck.DropDuplicateRows(getCsvArray1(), csvcheck.Options{}, 100)
+	assert.True(t, errors.As(err, &optionErr))
+	assert.Equal(t, "duplicate rows mode", optionErr.Option)
+}
@@ -0,0 +1,383 @@
+package csvcheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// For holding a parsed row filter expression such as `status != "cancelled" && amount > 0`.
+//
+// Comparisons use the operators ==, !=, <, <=, > and >= between column names, string
+// literals in double or single quotes, numbers and other value expressions, see
+// DerivedColumn. Column names that are not plain identifiers can be written in
+// backquotes, such as `unit price`. Values are compared as numbers if both sides are
+// numeric and as strings otherwise. Comparisons can be combined with &&, || and !,
+// and grouped with parentheses.
+type RowFilter struct {
+	expr string
+	root *filterNode
+}
+
+// For holding a node of a parsed row filter, either a logical operator or a comparison.
+type filterNode struct {
+	op       string
+	left     *filterNode
+	right    *filterNode
+	operands [2]*valueNode
+}
+
+// For holding a token of a row filter expression.
+type filterToken struct {
+	kind  int
+	value string
+	pos   int
+}
+
+// Kinds of tokens of row filter expressions.
+const (
+	filterTokenEnd = iota
+	filterTokenOperator
+	filterTokenColumn
+	filterTokenString
+	filterTokenNumber
+)
+
+// Returns true iff op compares two values.
+func isComparisonOperator(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// Returns true iff the token can end a value, so that a following minus sign subtracts.
+func (t filterToken) endsValue() bool {
+	return t.kind == filterTokenColumn || t.kind == filterTokenString || t.kind == filterTokenNumber ||
+		(t.kind == filterTokenOperator && t.value == ")")
+}
+
+// Returns the tokens of a row filter or value expression.
+func getFilterTokens(expr string) ([]filterToken, error) {
+	res := []filterToken{}
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		start := i
+		afterValue := len(res) > 0 && res[len(res)-1].endsValue()
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '"' || c == '\'' || c == '`':
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != c {
+				if runes[i] == '\\' && c != '`' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated quote at position %d", start)
+			}
+			i++
+			kind := filterTokenString
+			if c == '`' {
+				kind = filterTokenColumn
+			}
+			res = append(res, filterToken{kind: kind, value: sb.String(), pos: start})
+			continue
+		case unicode.IsDigit(c) || (((c == '-' && !afterValue) || c == '.') && i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.')):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' ||
+				((runes[i] == '-' || runes[i] == '+') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			s := string(runes[start:i])
+			if !isNumeric(s) {
+				return nil, fmt.Errorf("invalid number %s at position %d", s, start)
+			}
+			res = append(res, filterToken{kind: filterTokenNumber, value: s, pos: start})
+			continue
+		case unicode.IsLetter(c) || c == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			res = append(res, filterToken{kind: filterTokenColumn, value: string(runes[start:i]), pos: start})
+			continue
+		}
+
+		op := ""
+		if i+1 < len(runes) {
+			switch two := string(runes[i : i+2]); two {
+			case "&&", "||", "==", "!=", "<=", ">=":
+				op = two
+			}
+		}
+		if op == "" {
+			switch c {
+			case '(', ')', '!', '<', '>', '+', '-', '*', '/', ',':
+				op = string(c)
+			default:
+				return nil, fmt.Errorf("unexpected %q at position %d", c, start)
+			}
+		}
+		i += len(op)
+		res = append(res, filterToken{kind: filterTokenOperator, value: op, pos: start})
+	}
+	return append(res, filterToken{kind: filterTokenEnd, pos: len(runes)}), nil
+}
+
+// For parsing a row filter expression by recursive descent.
+type filterParser struct {
+	tokens []filterToken
+	i      int
+}
+
+// Returns the next token without consuming it.
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.i]
+}
+
+// Consumes the next token if it is the given operator.
+func (p *filterParser) accept(op string) bool {
+	t := p.peek()
+	if t.kind == filterTokenOperator && t.value == op {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// Returns an error describing the next token as unexpected.
+func (p *filterParser) unexpected() error {
+	t := p.peek()
+	if t.kind == filterTokenEnd {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %s at position %d", t.value, t.pos)
+}
+
+// Parses a sequence of operands joined by the given logical operator.
+func (p *filterParser) parseLogical(op string, parseNext func() (*filterNode, error)) (*filterNode, error) {
+	res, err := parseNext()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(op) {
+		right, err := parseNext()
+		if err != nil {
+			return nil, err
+		}
+		res = &filterNode{op: op, left: res, right: right}
+	}
+	return res, nil
+}
+
+// Parses operands joined by ||, which binds the loosest.
+func (p *filterParser) parseOr() (*filterNode, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+// Parses operands joined by &&.
+func (p *filterParser) parseAnd() (*filterNode, error) {
+	return p.parseLogical("&&", p.parseNot)
+}
+
+// Parses a negation, a parenthesized expression or a comparison.
+func (p *filterParser) parseNot() (*filterNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &filterNode{op: "!", left: operand}, nil
+	}
+	if p.peek().value == "(" {
+		// The parentheses may also group a value, as in (amount + 1) > 2.
+		start := p.i
+		p.i++
+		res, err := p.parseOr()
+		if err == nil && p.accept(")") && !p.peekValueOperator() {
+			return res, nil
+		}
+		p.i = start
+	}
+	return p.parseComparison()
+}
+
+// Checks if the next token is a comparison or arithmetic operator.
+func (p *filterParser) peekValueOperator() bool {
+	t := p.peek()
+	if t.kind != filterTokenOperator {
+		return false
+	}
+	switch t.value {
+	case "+", "-", "*", "/":
+		return true
+	}
+	return isComparisonOperator(t.value)
+}
+
+// Parses two operands joined by a comparison operator.
+func (p *filterParser) parseComparison() (*filterNode, error) {
+	left, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.kind != filterTokenOperator || !isComparisonOperator(t.value) {
+		return nil, p.unexpected()
+	}
+	p.i++
+	right, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return &filterNode{op: t.value, operands: [2]*valueNode{left, right}}, nil
+}
+
+// Returns the parsed row filter expression.
+func ParseRowFilter(expr string) (*RowFilter, error) {
+	tokens, err := getFilterTokens(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %w", expr, err)
+	}
+
+	p := filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != filterTokenEnd {
+		err = p.unexpected()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %w", expr, err)
+	}
+	return &RowFilter{expr: expr, root: root}, nil
+}
+
+// Returns the expression the filter was parsed from.
+func (f *RowFilter) String() string {
+	return f.expr
+}
+
+// Returns -1, 0 or 1 as a is less than, equal to or greater than b, comparing
+// them as numbers if both are numeric.
+func compareFilterValues(a, b string) int {
+	x, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// Returns a function evaluating the node on rows with the given header.
+func (n *filterNode) compile(header []StringHashable) (func([]StringHashable) bool, error) {
+	switch n.op {
+	case "&&", "||":
+		left, err := n.left.compile(header)
+		if err != nil {
+			return nil, err
+		}
+		right, err := n.right.compile(header)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "&&" {
+			return func(row []StringHashable) bool { return left(row) && right(row) }, nil
+		}
+		return func(row []StringHashable) bool { return left(row) || right(row) }, nil
+	case "!":
+		operand, err := n.left.compile(header)
+		if err != nil {
+			return nil, err
+		}
+		return func(row []StringHashable) bool { return !operand(row) }, nil
+	}
+
+	left, err := n.operands[0].compile(header)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.operands[1].compile(header)
+	if err != nil {
+		return nil, err
+	}
+	op := n.op
+	return func(row []StringHashable) bool {
+		c := compareFilterValues(left(row), right(row))
+		switch op {
+		case "==":
+			return c == 0
+		case "!=":
+			return c != 0
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		}
+		return c >= 0
+	}, nil
+}
+
+// Returns a function checking if a row with the given header matches the filter.
+// Returns a ColumnNotFoundError if the filter refers to a column missing from the header.
+func (f *RowFilter) Compile(header []StringHashable) (func([]StringHashable) bool, error) {
+	return f.root.compile(header)
+}
+
+// Returns the indices of the rows below the header of the array that match the filter expression.
+func FilterRows(arr [][]StringHashable, where string) ([]int, error) {
+	err := CheckForProperCsvArray(arr)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := ParseRowFilter(where)
+	if err != nil {
+		return nil, err
+	}
+	match, err := filter.Compile(arr[0])
+	if err != nil {
+		return nil, err
+	}
+
+	res := []int{}
+	for i := 1; i < len(arr); i++ {
+		if match(arr[i]) {
+			res = append(res, i)
+		}
+	}
+	return res, nil
+}
+
+// Returns the array to compare without the rows below the header that do not match
+// the filter, dropping their indices from the prepared array as well.
+func filterComparedRows(c *comparedArray, arr [][]StringHashable, filter *RowFilter) ([][]StringHashable, error) {
+	match, err := filter.Compile(arr[0])
+	if err != nil {
+		return nil, err
+	}
+
+	res := [][]StringHashable{arr[0]}
+	indices := []int{}
+	for i, row := range arr[1:] {
+		if match(row) {
+			res = append(res, row)
+			indices = append(indices, c.indices[i])
+		}
+	}
+	c.indices = indices
+	return res, nil
+}
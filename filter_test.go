@@ -0,0 +1,113 @@
+package csvcheck_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getOrdersCsvArray() [][]csvcheck.StringHashable {
+	return csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "status", "amount", "unit price"},
+		{"1", "shipped", "10", "2.5"},
+		{"2", "cancelled", "5", "1"},
+		{"3", "shipped", "0", "3"},
+		{"4", "pending", "12.5", "10"},
+		{"5", "it's \"new\"", "-1", "4"},
+	})
+}
+
+func TestFilterRows(t *testing.T) {
+	arr := getOrdersCsvArray()
+	tests := []struct {
+		where    string
+		expected []int
+	}{
+		{`status != "cancelled" && amount > 0`, []int{1, 4}},
+		{`status == 'shipped' || amount >= 12`, []int{1, 3, 4}},
+		{`!(status == "shipped")`, []int{2, 4, 5}},
+		{"`unit price` < amount", []int{1, 2, 4}},
+		{`amount < -0.5`, []int{5}},
+		{`status == 'it\'s "new"'`, []int{5}},
+		{`amount > 2 && (id == 1 || id == 4) && !(status == "pending")`, []int{1}},
+		{`status > "p"`, []int{1, 3, 4}},
+		{`1e1 == amount`, []int{1}},
+	}
+	for _, test := range tests {
+		res, err := csvcheck.FilterRows(arr, test.where)
+		assert.Nil(t, err, test.where)
+		assert.Equal(t, test.expected, res, test.where)
+	}
+}
+
+func TestParseRowFilterInvalid(t *testing.T) {
+	invalid := []string{
+		``,
+		`status`,
+		`status = "x"`,
+		`status == "x`,
+		`(amount > 1`,
+		`amount > 1)`,
+		`amount > 1 &&`,
+		`amount > 1 & id == 2`,
+		`amount > 1.2.3`,
+		`amount == == 1`,
+	}
+	for _, where := range invalid {
+		_, err := csvcheck.ParseRowFilter(where)
+		assert.NotNil(t, err, where)
+	}
+
+	filter, err := csvcheck.ParseRowFilter(`amount > 1`)
+	assert.Nil(t, err)
+	assert.Equal(t, `amount > 1`, filter.String())
+}
+
+func TestFilterRowsMissingColumn(t *testing.T) {
+	_, err := csvcheck.FilterRows(getOrdersCsvArray(), `total > 1`)
+
+	var notFound *csvcheck.ColumnNotFoundError
+	assert.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "total", notFound.Name)
+}
+
+func TestGetDifferentRowsWhere(t *testing.T) {
+	arr1 := getOrdersCsvArray()
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"ID", "status", "amount", "unit price"},
+		{"2", "cancelled", "6", "1"},
+		{"1", "shipped", "10", "2.5"},
+		{"4", "pending", "12.5", "10"},
+	})
+	options := csvcheck.Options{
+		SortIndices:   true,
+		ColumnMapping: map[string]string{"id": "ID"},
+		Where:         `status != "cancelled" && amount > 0 && id < 5`,
+	}
+
+	_, _, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0}, indices1)
+	assert.Equal(t, []int{0}, indices2)
+
+	options.Where = `amount > 0`
+	_, _, indices1, indices2, err = csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2}, indices1)
+	assert.Equal(t, []int{0, 1}, indices2)
+
+	options.Where = `col2 == "shipped"`
+	options.NoHeader = true
+	options.ColumnMapping = nil
+	_, _, indices1, indices2, err = csvcheck.GetCommonRows(arr1[1:], arr2[1:], options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0}, indices1)
+	assert.Equal(t, []int{1}, indices2)
+
+	options.Where = `amount >`
+	_, _, _, _, err = csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,182 @@
+package csvcheck
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Supported trim rules for fixed-width columns.
+const (
+	TrimBoth = iota
+	TrimNone
+	TrimLeft
+	TrimRight
+)
+
+// For describing a column of a fixed-width file.
+type FixedWidthColumn struct {
+	Name  string
+	Start int  // The zero based offset of the first character of the column.
+	Width int  // The number of characters in the column.
+	Trim  int  // Which sides of the value to remove Pad characters from.
+	Pad   rune // The padding character. Use 0 for spaces.
+}
+
+// For describing the layout of a fixed-width file.
+type FixedWidthLayout struct {
+	Columns   []FixedWidthColumn
+	SkipLines int  // The number of lines at the start of the file to skip, such as banners.
+	Strict    bool // Fail on lines too short for the layout instead of treating the missing characters as padding.
+}
+
+// Checks if the layout is valid. Column spans must not overlap.
+func (l *FixedWidthLayout) CheckAttributes() error {
+	if len(l.Columns) == 0 {
+		return &InvalidOptionError{Option: "layout", Reason: "no columns"}
+	}
+	if l.SkipLines < 0 {
+		return &InvalidOptionError{Option: "skip lines", Value: fmt.Sprint(l.SkipLines), Reason: "must be non-negative"}
+	}
+
+	names := make(map[string]bool)
+	for _, column := range l.Columns {
+		if names[column.Name] {
+			return &DuplicateColumnError{Name: column.Name}
+		}
+		names[column.Name] = true
+
+		if column.Start < 0 || column.Width <= 0 {
+			return &InvalidOptionError{Option: "span of column " + column.Name, Value: column.getSpan(), Reason: "must have a non-negative start and a positive width"}
+		}
+		if column.Trim != TrimBoth && column.Trim != TrimNone && column.Trim != TrimLeft && column.Trim != TrimRight {
+			return &InvalidOptionError{Option: "trim of column " + column.Name, Value: fmt.Sprint(column.Trim)}
+		}
+	}
+
+	columns := slices.Clone(l.Columns)
+	slices.SortStableFunc(columns, func(a, b FixedWidthColumn) int { return a.Start - b.Start })
+	for i := 1; i < len(columns); i++ {
+		if previous := columns[i-1]; previous.Start+previous.Width > columns[i].Start {
+			return &InvalidOptionError{Option: "span of column " + columns[i].Name, Value: columns[i].getSpan(), Reason: "overlaps column " + previous.Name}
+		}
+	}
+	return nil
+}
+
+// Returns the span of the column as its start and width, such as "start 5, width 8".
+func (c *FixedWidthColumn) getSpan() string {
+	return fmt.Sprintf("start %d, width %d", c.Start, c.Width)
+}
+
+// Returns the end of the last column of the layout.
+func (l *FixedWidthLayout) getLineWidth() int {
+	res := 0
+	for _, column := range l.Columns {
+		res = max(res, column.Start+column.Width)
+	}
+	return res
+}
+
+// Returns the value of the column in a line according to its trim and pad rules.
+func (c *FixedWidthColumn) getValue(line []rune) string {
+	start := min(c.Start, len(line))
+	end := min(c.Start+c.Width, len(line))
+	s := string(line[start:end])
+
+	pad := string(c.Pad)
+	if c.Pad == 0 {
+		pad = " "
+	}
+	switch c.Trim {
+	case TrimBoth:
+		s = strings.TrimRight(strings.TrimLeft(s, pad), pad)
+	case TrimLeft:
+		s = strings.TrimLeft(s, pad)
+	case TrimRight:
+		s = strings.TrimRight(s, pad)
+	}
+	return s
+}
+
+// Returns the header row synthesized from the names of the columns of the layout.
+func (l *FixedWidthLayout) GetHeader() []StringHashable {
+	res := make([]StringHashable, len(l.Columns))
+	for i, column := range l.Columns {
+		res[i] = BasicStringHashable(column.Name)
+	}
+	return res
+}
+
+// Reads a UTF-8 fixed-width stream into a csv array with a header row made of the
+// names of the columns of the layout. Offsets count characters, not bytes. Blank
+// lines are skipped.
+func ReadFixedWidth(r io.Reader, layout FixedWidthLayout) ([][]StringHashable, error) {
+	err := layout.CheckAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	lineWidth := layout.getLineWidth()
+	res := [][]StringHashable{layout.GetHeader()}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if lineNumber <= layout.SkipLines {
+			continue
+		}
+
+		s := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+
+		line := []rune(s)
+		if layout.Strict && len(line) < lineWidth {
+			return nil, fmt.Errorf("line %d has %d characters, expected at least %d", lineNumber, len(line), lineWidth)
+		}
+
+		row := make([]StringHashable, len(layout.Columns))
+		for i, column := range layout.Columns {
+			row[i] = BasicStringHashable(column.getValue(line))
+		}
+		res = append(res, row)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Reads a fixed-width file into a csv array. The file is decompressed and transcoded
+// to UTF-8 as needed, see ReadCsvFile.
+func ReadFixedWidthFile(name string, layout FixedWidthLayout) ([][]StringHashable, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, _, err := newDecompressingFileReader(f, name, CompressionAuto)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer zr.Close()
+
+	r, _, err := NewUtf8Reader(zr, EncodingAuto)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	res, err := ReadFixedWidth(r, layout)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return res, nil
+}
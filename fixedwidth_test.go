@@ -0,0 +1,115 @@
+package csvcheck_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getFixedWidthLayout() csvcheck.FixedWidthLayout {
+	return csvcheck.FixedWidthLayout{
+		Columns: []csvcheck.FixedWidthColumn{
+			{Name: "id", Start: 0, Width: 5, Trim: csvcheck.TrimLeft, Pad: '0'},
+			{Name: "name", Start: 5, Width: 8},
+			{Name: "code", Start: 13, Width: 4, Trim: csvcheck.TrimNone},
+			{Name: "amount", Start: 17, Width: 7},
+		},
+		SkipLines: 1,
+	}
+}
+
+func TestReadFixedWidth(t *testing.T) {
+	s := "DAILY FEED 2024-01-02\r\n" +
+		"00001Zoë     AB    12.50\r\n" +
+		"\r\n" +
+		"00042Bob     C D   -3.00\r\n" +
+		"00100Al      XY\r\n"
+
+	res, err := csvcheck.ReadFixedWidth(strings.NewReader(s), getFixedWidthLayout())
+
+	expected := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "name", "code", "amount"},
+		{"1", "Zoë", "AB  ", "12.50"},
+		{"42", "Bob", "C D ", "-3.00"},
+		{"100", "Al", "XY", ""},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestReadFixedWidthStrictShortLine(t *testing.T) {
+	layout := getFixedWidthLayout()
+	layout.Strict = true
+	s := "banner\n00001Zoë     AB    12.50\n00100Al      XY\n"
+
+	_, err := csvcheck.ReadFixedWidth(strings.NewReader(s), layout)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 3")
+}
+
+func TestReadFixedWidthInvalidLayout(t *testing.T) {
+	layouts := []csvcheck.FixedWidthLayout{
+		{},
+		{Columns: []csvcheck.FixedWidthColumn{{Name: "a", Start: 0, Width: 0}}},
+		{Columns: []csvcheck.FixedWidthColumn{{Name: "a", Start: -1, Width: 2}}},
+		{Columns: []csvcheck.FixedWidthColumn{{Name: "a", Start: 0, Width: 2}, {Name: "a", Start: 2, Width: 2}}},
+		{Columns: []csvcheck.FixedWidthColumn{{Name: "a", Start: 0, Width: 2, Trim: 10}}},
+		{Columns: []csvcheck.FixedWidthColumn{{Name: "a", Start: 0, Width: -2}}},
+		{Columns: []csvcheck.FixedWidthColumn{{Name: "a", Start: 2, Width: 2}, {Name: "b", Start: 0, Width: 3}}},
+	}
+
+	for _, layout := range layouts {
+		_, err := csvcheck.ReadFixedWidth(strings.NewReader("abcd\n"), layout)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestFixedWidthLayoutCheckAttributesErrors(t *testing.T) {
+	layout := csvcheck.FixedWidthLayout{Columns: []csvcheck.FixedWidthColumn{{Name: "a", Start: 0, Width: 2}, {Name: "a", Start: 2, Width: 2}}}
+	var duplicateErr *csvcheck.DuplicateColumnError
+	assert.ErrorAs(t, layout.CheckAttributes(), &duplicateErr)
+	assert.Equal(t, "a", duplicateErr.Name)
+
+	layout = csvcheck.FixedWidthLayout{Columns: []csvcheck.FixedWidthColumn{{Name: "a", Start: 2, Width: 2}, {Name: "b", Start: 0, Width: 3}}}
+	err := layout.CheckAttributes()
+	var optionErr *csvcheck.InvalidOptionError
+	assert.ErrorAs(t, err, &optionErr)
+	assert.Equal(t, "invalid span of column a: overlaps column b", err.Error())
+
+	layout = csvcheck.FixedWidthLayout{Columns: []csvcheck.FixedWidthColumn{{Name: "a", Start: 0, Width: -1}}}
+	assert.ErrorAs(t, layout.CheckAttributes(), &optionErr)
+	assert.Equal(t, "span of column a", optionErr.Option)
+	assert.Equal(t, "start 0, width -1", optionErr.Value)
+
+	layout = getFixedWidthLayout()
+	assert.Nil(t, layout.CheckAttributes())
+}
+
+func TestReadFixedWidthFileThroughColumnFunctions(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "feed.txt")
+	assert.Nil(t, os.WriteFile(name, []byte("HEADER\n00001Zoe     AB    12.50\n00042Bob     CD    -3.00\n"), 0o644))
+
+	arr1, err := csvcheck.ReadFixedWidthFile(name, getFixedWidthLayout())
+	assert.Nil(t, err)
+
+	arr1, err = csvcheck.RearrangeColumns(arr1, csvcheck.GetRowFromRow([]string{"amount", "code", "name", "id"}))
+	assert.Nil(t, err)
+	arr1, err = csvcheck.KeepColumns(arr1, csvcheck.GetRowFromRow([]string{"id", "amount"}))
+	assert.Nil(t, err)
+
+	arr2 := Get2DArrayFromCsvString(`
+id,amount
+1,12.50
+42,-3.50
+`)
+	_, _, indices1, indices2, err := csvcheck.GetCommonRows(arr1, arr2, csvcheck.Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1}, indices1)
+	assert.Equal(t, []int{0, 1}, indices2)
+}
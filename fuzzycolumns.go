@@ -0,0 +1,265 @@
+package csvcheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// For holding the options used when pairing up columns by similarity.
+type ColumnMatchOptions struct {
+	NameWeight    float64 // The weight of name similarity against content similarity, between 0 and 1.
+	MinConfidence float64 // Pairings with a lower confidence are not proposed.
+	SampleSize    int     // The number of rows below the header used for content similarity. Use 0 for all rows.
+}
+
+// Reasonable options for SuggestColumnPairings and AutoAlignCsvArraysFuzzy.
+var DefaultColumnMatchOptions = ColumnMatchOptions{NameWeight: 0.5, MinConfidence: 0.5, SampleSize: 1000}
+
+// For holding a proposed pairing of a left column with a right column.
+type ColumnPairing struct {
+	Left         StringHashable
+	Right        StringHashable
+	Confidence   float64 // Between 0 and 1, combining NameScore and ContentScore.
+	NameScore    float64
+	ContentScore float64
+}
+
+// Checks if the options are valid.
+func (o *ColumnMatchOptions) CheckAttributes() error {
+	if o.NameWeight < 0 || o.NameWeight > 1 {
+		return &InvalidOptionError{Option: "name weight", Value: fmt.Sprint(o.NameWeight), Reason: "must be between 0 and 1"}
+	}
+	if o.MinConfidence < 0 || o.MinConfidence > 1 {
+		return &InvalidOptionError{Option: "minimum confidence", Value: fmt.Sprint(o.MinConfidence), Reason: "must be between 0 and 1"}
+	}
+	if o.SampleSize < 0 {
+		return &InvalidOptionError{Option: "sample size", Value: fmt.Sprint(o.SampleSize), Reason: "must be non-negative"}
+	}
+	return nil
+}
+
+// Returns the lower case words of a column name, splitting on punctuation,
+// whitespace and camel case, such as "cust" and "id" for "custID".
+func getNameTokens(name string) []string {
+	tokens := []string{}
+	var current []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(current) > 0 {
+			tokens = append(tokens, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	for i, c := range runes {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			flush()
+			continue
+		}
+		if i > 0 && len(current) > 0 {
+			previous := runes[i-1]
+			lowerToUpper := unicode.IsLower(previous) && unicode.IsUpper(c)
+			acronymEnd := unicode.IsUpper(previous) && unicode.IsUpper(c) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			letterDigit := unicode.IsDigit(previous) != unicode.IsDigit(c)
+			if lowerToUpper || acronymEnd || letterDigit {
+				flush()
+			}
+		}
+		current = append(current, c)
+	}
+	flush()
+	return tokens
+}
+
+// Returns true iff the tokens are equal or one abbreviates the other, like "cust" and
+// "customer" or "amt" and "amount". Abbreviations start with the same letter and keep
+// the order of the letters of the full word.
+func tokensMatch(token1, token2 string) bool {
+	if token1 == token2 {
+		return true
+	}
+	shorter, longer := []rune(token1), []rune(token2)
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+	if len(shorter) < 3 || shorter[0] != longer[0] {
+		return false
+	}
+
+	i := 0
+	for _, c := range longer {
+		if i < len(shorter) && shorter[i] == c {
+			i++
+		}
+	}
+	return i == len(shorter)
+}
+
+// Returns the similarity of two column names between 0 and 1, the better of
+// the edit distance between their normalized forms and the overlap of their words.
+func getNameSimilarity(name1, name2 string) float64 {
+	tokens1 := getNameTokens(name1)
+	tokens2 := getNameTokens(name2)
+	if len(tokens1) == 0 || len(tokens2) == 0 {
+		return 0
+	}
+
+	normalized1 := strings.Join(tokens1, "")
+	normalized2 := strings.Join(tokens2, "")
+	longest := max(len([]rune(normalized1)), len([]rune(normalized2)))
+	editScore := 1 - float64(getEditDistance(normalized1, normalized2))/float64(longest)
+
+	matched := 0
+	used := make([]bool, len(tokens2))
+	for _, token1 := range tokens1 {
+		for j, token2 := range tokens2 {
+			if !used[j] && tokensMatch(token1, token2) {
+				used[j] = true
+				matched++
+				break
+			}
+		}
+	}
+	tokenScore := float64(matched) / float64(max(len(tokens1), len(tokens2)))
+
+	return max(editScore, tokenScore)
+}
+
+// Returns the distinct non-empty values of a column and the fraction of the non-empty
+// values that are numeric. Empty values tell nothing about which columns match.
+func getColumnProfile(arr [][]StringHashable, column, sampleSize int) (map[uint64]bool, float64) {
+	values := make(map[uint64]bool)
+	numeric := 0
+	filled := 0
+	rows := arr[1:]
+	if sampleSize > 0 && len(rows) > sampleSize {
+		rows = rows[:sampleSize]
+	}
+	for _, row := range rows {
+		s := row[column].StringHash()
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		if isNumeric(s) {
+			numeric++
+		}
+		values[getStringKey(row[column])] = true
+		filled++
+	}
+	if filled == 0 {
+		return values, 0
+	}
+	return values, float64(numeric) / float64(filled)
+}
+
+// Returns the similarity of the contents of two columns between 0 and 1, mostly from
+// the overlap of their distinct values and partly from how many of them are numeric.
+func getContentSimilarity(values1, values2 map[uint64]bool, numeric1, numeric2 float64) float64 {
+	if len(values1) == 0 || len(values2) == 0 {
+		return 0
+	}
+
+	common := 0
+	for value := range values1 {
+		if values2[value] {
+			common++
+		}
+	}
+	overlap := float64(common) / float64(len(values1)+len(values2)-common)
+	typeAgreement := 1 - max(numeric1-numeric2, numeric2-numeric1)
+	return 0.8*overlap + 0.2*typeAgreement
+}
+
+// Returns proposed pairings of the columns of csvArray1 and csvArray2 that have no
+// column of the same name on the other side, ordered by decreasing confidence. Each
+// column is paired at most once. The pairings can be turned into a column mapping
+// with GetColumnMappingFromPairings once accepted.
+func SuggestColumnPairings(csvArray1, csvArray2 [][]StringHashable, options ColumnMatchOptions) ([]ColumnPairing, error) {
+	err := CheckForProperCsvArray(csvArray1)
+	if err != nil {
+		return nil, err
+	}
+	err = CheckForProperCsvArray(csvArray2)
+	if err != nil {
+		return nil, err
+	}
+	err = options.CheckAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	onlyLeft, onlyRight := getColumnsOnlyInEither(csvArray1[0], csvArray2[0])
+	leftIndices, _ := getColumnIndices(csvArray1[0], onlyLeft)
+	rightIndices, _ := getColumnIndices(csvArray2[0], onlyRight)
+
+	type profile struct {
+		values  map[uint64]bool
+		numeric float64
+	}
+	profiles2 := make([]profile, len(rightIndices))
+	for j, index := range rightIndices {
+		profiles2[j].values, profiles2[j].numeric = getColumnProfile(csvArray2, index, options.SampleSize)
+	}
+
+	candidates := []ColumnPairing{}
+	for i, index := range leftIndices {
+		values1, numeric1 := getColumnProfile(csvArray1, index, options.SampleSize)
+		for j := range rightIndices {
+			nameScore := getNameSimilarity(onlyLeft[i].StringHash(), onlyRight[j].StringHash())
+			contentScore := getContentSimilarity(values1, profiles2[j].values, numeric1, profiles2[j].numeric)
+			candidates = append(candidates, ColumnPairing{
+				Left:         onlyLeft[i],
+				Right:        onlyRight[j],
+				Confidence:   options.NameWeight*nameScore + (1-options.NameWeight)*contentScore,
+				NameScore:    nameScore,
+				ContentScore: contentScore,
+			})
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].Confidence > candidates[b].Confidence
+	})
+
+	res := []ColumnPairing{}
+	usedLeft := make(map[uint64]bool)
+	usedRight := make(map[uint64]bool)
+	for _, candidate := range candidates {
+		left := getStringKey(candidate.Left)
+		right := getStringKey(candidate.Right)
+		if candidate.Confidence < options.MinConfidence || usedLeft[left] || usedRight[right] {
+			continue
+		}
+		usedLeft[left] = true
+		usedRight[right] = true
+		res = append(res, candidate)
+	}
+	return res, nil
+}
+
+// Returns a column mapping for Options.ColumnMapping from accepted pairings.
+func GetColumnMappingFromPairings(pairings []ColumnPairing) map[string]string {
+	res := make(map[string]string)
+	for _, pairing := range pairings {
+		res[pairing.Left.StringHash()] = pairing.Right.StringHash()
+	}
+	return res
+}
+
+// Aligns the columns of the arrays like AutoAlignCsvArrays, additionally pairing up
+// columns that only differ in name according to SuggestColumnPairings. Also returns
+// the pairings used, so they can be reviewed and passed to AutoAlignCsvArraysWithMapping
+// after rejecting any of them.
+func AutoAlignCsvArraysFuzzy(csvArray1, csvArray2 [][]StringHashable, options ColumnMatchOptions) ([][]StringHashable, [][]StringHashable, []ColumnPairing, error) {
+	pairings, err := SuggestColumnPairings(csvArray1, csvArray2, options)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	res1, res2, err := AutoAlignCsvArraysWithMapping(csvArray1, csvArray2, GetColumnMappingFromPairings(pairings))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return res1, res2, pairings, nil
+}
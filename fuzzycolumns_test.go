@@ -0,0 +1,82 @@
+package csvcheck_test
+
+import (
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getFuzzyColumnsCsvArrays() ([][]csvcheck.StringHashable, [][]csvcheck.StringHashable) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"cust_id", "name", "Amount", "city"},
+		{"1", "x", "10.5", "Paris"},
+		{"2", "y", "20", "Oslo"},
+		{"3", "z", "30", "Rome"},
+	})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"Town", "CustomerID", "name", "amt", "note"},
+		{"Oslo", "2", "y", "20", "hello"},
+		{"Paris", "1", "x", "10.5", "world"},
+		{"Rome", "3", "z", "31", "!"},
+	})
+	return arr1, arr2
+}
+
+func TestSuggestColumnPairings(t *testing.T) {
+	arr1, arr2 := getFuzzyColumnsCsvArrays()
+
+	pairings, err := csvcheck.SuggestColumnPairings(arr1, arr2, csvcheck.DefaultColumnMatchOptions)
+
+	assert.Nil(t, err)
+	mapping := csvcheck.GetColumnMappingFromPairings(pairings)
+	assert.Equal(t, map[string]string{"cust_id": "CustomerID", "Amount": "amt", "city": "Town"}, mapping)
+	assert.Equal(t, "cust_id", pairings[0].Left.StringHash())
+	assert.Equal(t, 1.0, pairings[0].NameScore)
+	assert.Equal(t, 1.0, pairings[0].Confidence)
+	for i := 1; i < len(pairings); i++ {
+		assert.GreaterOrEqual(t, pairings[i-1].Confidence, pairings[i].Confidence)
+	}
+}
+
+func TestSuggestColumnPairingsNameOnly(t *testing.T) {
+	arr1, arr2 := getFuzzyColumnsCsvArrays()
+	options := csvcheck.ColumnMatchOptions{NameWeight: 1, MinConfidence: 0.9}
+
+	pairings, err := csvcheck.SuggestColumnPairings(arr1, arr2, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"cust_id": "CustomerID", "Amount": "amt"}, csvcheck.GetColumnMappingFromPairings(pairings))
+}
+
+func TestSuggestColumnPairingsEmptyColumns(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{{"id", "foo"}, {"1", ""}, {"2", ""}})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{{"id", "bar"}, {"1", ""}, {"2", " "}})
+
+	pairings, err := csvcheck.SuggestColumnPairings(arr1, arr2, csvcheck.ColumnMatchOptions{NameWeight: 0.5})
+
+	assert.Nil(t, err)
+	assert.Len(t, pairings, 1)
+	assert.Equal(t, 0.0, pairings[0].ContentScore)
+}
+
+func TestSuggestColumnPairingsInvalidOptions(t *testing.T) {
+	arr1, arr2 := getFuzzyColumnsCsvArrays()
+
+	_, err := csvcheck.SuggestColumnPairings(arr1, arr2, csvcheck.ColumnMatchOptions{NameWeight: 2})
+
+	assert.NotNil(t, err)
+}
+
+func TestAutoAlignCsvArraysFuzzy(t *testing.T) {
+	arr1, arr2 := getFuzzyColumnsCsvArrays()
+
+	res1, res2, pairings, err := csvcheck.AutoAlignCsvArraysFuzzy(arr1, arr2, csvcheck.DefaultColumnMatchOptions)
+
+	assert.Nil(t, err)
+	assert.Len(t, pairings, 3)
+	assert.Equal(t, arr1, res1)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"CustomerID", "name", "amt", "Town", "note"}), res2[0])
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"2", "y", "20", "Oslo", "hello"}), res2[1])
+}
@@ -0,0 +1,257 @@
+package csvcheck
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The score used by MethodFuzzy when RowMatchOptions.Threshold is 0.
+const DefaultRowMatchThreshold = 0.8
+
+// Blocking keys shared by more rows than this on either side are not used to find
+// candidate pairs with MethodFuzzy, as they tell little about which rows match.
+const RowMatchMaxBlockSize = 50
+
+// For holding the options used when pairing up rows by similarity with MethodFuzzy.
+type RowMatchOptions struct {
+	Threshold    float64            // Pairs of rows with a lower score are not matched. Uses DefaultRowMatchThreshold if 0.
+	Weights      map[string]float64 // The weights of the compared columns by name in the score. Columns not given weigh 1.
+	BlockColumns []StringHashable   // Only rows with equal values in these columns are paired. Uses the words of all values if nil.
+}
+
+// For holding a pair of rows matched approximately by MethodFuzzy.
+type RowPair struct {
+	Index1 int     // The index of the row in the first array.
+	Index2 int     // The index of the row in the second array.
+	Score  float64 // The weighted similarity of the rows, between the threshold and 1.
+}
+
+// Checks if the options are valid.
+func (o *RowMatchOptions) CheckAttributes() error {
+	if o.Threshold < 0 || o.Threshold > 1 {
+		return &InvalidOptionError{Option: "row match threshold", Value: fmt.Sprint(o.Threshold), Reason: "must be between 0 and 1"}
+	}
+	for name, weight := range o.Weights {
+		if weight < 0 {
+			return &InvalidOptionError{Option: "weight of column " + name, Value: fmt.Sprint(weight), Reason: "must be non-negative"}
+		}
+	}
+	return nil
+}
+
+// Returns the similarity of two values between 0 and 1: how close they are if both are
+// numeric, and otherwise one minus their edit distance relative to the longer one,
+// ignoring case and surrounding whitespace.
+func getValueSimilarity(value1, value2 string) float64 {
+	s1 := strings.ToLower(strings.TrimSpace(value1))
+	s2 := strings.ToLower(strings.TrimSpace(value2))
+	if s1 == s2 {
+		return 1
+	}
+
+	x, err1 := strconv.ParseFloat(s1, 64)
+	y, err2 := strconv.ParseFloat(s2, 64)
+	if err1 == nil && err2 == nil {
+		largest := max(math.Abs(x), math.Abs(y))
+		if largest == 0 {
+			return 1
+		}
+		return max(0, 1-math.Abs(x-y)/largest)
+	}
+
+	longest := max(len([]rune(s1)), len([]rune(s2)))
+	return 1 - float64(getEditDistance(s1, s2))/float64(longest)
+}
+
+// Returns the weighted similarity of two rows between 0 and 1.
+func getRowSimilarity(row1, row2 []StringHashable, weights []float64) float64 {
+	total := 0.0
+	score := 0.0
+	for i := range row1 {
+		weight := 1.0
+		if weights != nil {
+			weight = weights[i]
+		}
+		if weight == 0 {
+			continue
+		}
+		total += weight
+		score += weight * getValueSimilarity(row1[i].StringHash(), row2[i].StringHash())
+	}
+	if total == 0 {
+		return 0
+	}
+	return score / total
+}
+
+// Returns the blocking keys of a row: the values of the block columns joined together,
+// or the lower case words of all values if there are no block columns.
+func getBlockingKeys(row []StringHashable, blockIndices []int) []string {
+	if blockIndices != nil {
+		values := make([]string, len(blockIndices))
+		for i, index := range blockIndices {
+			values[i] = strings.ToLower(strings.TrimSpace(row[index].StringHash()))
+		}
+		return []string{strings.Join(values, "\x00")}
+	}
+
+	res := []string{}
+	for i, value := range row {
+		words := strings.FieldsFunc(strings.ToLower(value.StringHash()), func(c rune) bool {
+			return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+		})
+		for _, word := range words {
+			res = append(res, strconv.Itoa(i)+":"+word)
+		}
+	}
+	return res
+}
+
+// Returns the pairs of rows of arr1 at indices1 and of arr2 at indices2 scoring at least
+// threshold, pairing each row at most once, best scores first. Only rows sharing a
+// blocking key are scored.
+func getFuzzyRowPairs(ctx context.Context, arr1, arr2 [][]StringHashable, indices1, indices2 []int, weights []float64, blockIndices []int, threshold float64) ([]RowPair, error) {
+	blocks1 := make(map[string][]int)
+	for _, i := range indices1 {
+		for _, key := range getBlockingKeys(arr1[i], blockIndices) {
+			blocks1[key] = append(blocks1[key], i)
+		}
+	}
+	blocks2 := make(map[string][]int)
+	for _, i := range indices2 {
+		for _, key := range getBlockingKeys(arr2[i], blockIndices) {
+			blocks2[key] = append(blocks2[key], i)
+		}
+	}
+
+	scored := make(map[[2]int]bool)
+	candidates := []RowPair{}
+	for key, block1 := range blocks1 {
+		block2 := blocks2[key]
+		if len(block2) == 0 || (blockIndices == nil && (len(block1) > RowMatchMaxBlockSize || len(block2) > RowMatchMaxBlockSize)) {
+			continue
+		}
+		err := ctx.Err()
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range block1 {
+			for _, j := range block2 {
+				if scored[[2]int{i, j}] {
+					continue
+				}
+				scored[[2]int{i, j}] = true
+				score := getRowSimilarity(arr1[i], arr2[j], weights)
+				if score >= threshold {
+					candidates = append(candidates, RowPair{Index1: i, Index2: j, Score: score})
+				}
+			}
+		}
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].Score != candidates[b].Score {
+			return candidates[a].Score > candidates[b].Score
+		}
+		if candidates[a].Index1 != candidates[b].Index1 {
+			return candidates[a].Index1 < candidates[b].Index1
+		}
+		return candidates[a].Index2 < candidates[b].Index2
+	})
+
+	res := []RowPair{}
+	used1 := make(map[int]bool)
+	used2 := make(map[int]bool)
+	for _, candidate := range candidates {
+		if used1[candidate.Index1] || used2[candidate.Index2] {
+			continue
+		}
+		used1[candidate.Index1] = true
+		used2[candidate.Index2] = true
+		res = append(res, candidate)
+	}
+	return res, nil
+}
+
+// Compares the rows of the arrays with MethodFuzzy given their keys: rows are first
+// matched exactly like MethodMatch, then the remaining rows are paired by similarity.
+// The compared columns are given by header, which the arrays do not include.
+func (c *rowsComparison) matchFuzzy(ctx context.Context, arr1, arr2 [][]StringHashable, header []StringHashable, options RowMatchOptions) error {
+	err := options.CheckAttributes()
+	if err != nil {
+		return err
+	}
+	threshold := options.Threshold
+	if threshold == 0 {
+		threshold = DefaultRowMatchThreshold
+	}
+
+	var weights []float64
+	if options.Weights != nil {
+		weights = make([]float64, len(header))
+		for i, column := range header {
+			weights[i] = 1
+			if weight, exists := options.Weights[column.StringHash()]; exists {
+				weights[i] = weight
+			}
+		}
+	}
+	var blockIndices []int
+	if options.BlockColumns != nil {
+		columns, err := resolveColumnIndices(header, options.BlockColumns)
+		if err != nil {
+			return err
+		}
+		blockIndices, err = getColumnIndices(header, columns)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.common1, c.common2 = getCommonIndicesMatch(c.mapping1, c.mapping2)
+	different1, different2 := getDifferentIndicesMatch(c.mapping1, c.mapping2)
+	sort.Ints(different1)
+	sort.Ints(different2)
+	c.pairs, err = getFuzzyRowPairs(ctx, arr1, arr2, different1, different2, weights, blockIndices, threshold)
+	if err != nil {
+		return err
+	}
+
+	paired1 := make(map[int]bool)
+	paired2 := make(map[int]bool)
+	for _, pair := range c.pairs {
+		c.common1 = append(c.common1, pair.Index1)
+		c.common2 = append(c.common2, pair.Index2)
+		paired1[pair.Index1] = true
+		paired2[pair.Index2] = true
+	}
+	c.different1 = []int{}
+	for _, i := range different1 {
+		if !paired1[i] {
+			c.different1 = append(c.different1, i)
+		}
+	}
+	c.different2 = []int{}
+	for _, i := range different2 {
+		if !paired2[i] {
+			c.different2 = append(c.different2, i)
+		}
+	}
+	return nil
+}
+
+// Returns the pairs of rows matched approximately when comparing the arrays with
+// MethodFuzzy, with the indices of the rows in the original arrays. Rows that are
+// equal are not included.
+func GetFuzzyRowPairs(csvArray1, csvArray2 [][]StringHashable, options Options) ([]RowPair, error) {
+	options.Method = MethodFuzzy
+	res, err := GetCommonRowsResult(context.Background(), csvArray1, csvArray2, options, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Pairs, nil
+}
@@ -0,0 +1,126 @@
+package csvcheck_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getAddressesCsvArrays() ([][]csvcheck.StringHashable, [][]csvcheck.StringHashable) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"name", "street", "city", "balance"},
+		{"Ada Lovelace", "12 Main Street", "London", "100"},
+		{"Alan Turing", "3 Park Lane", "Wilmslow", "250"},
+		{"Grace Hopper", "7 Navy Road", "Arlington", "75"},
+		{"Edsger Dijkstra", "1 Canal", "Nuenen", "10"},
+	})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"name", "street", "city", "balance"},
+		{"Alan Turing", "3 Park Lane", "Wilmslow", "250"},
+		{"Grace Hoper", "7 Navy Rd", "Arlington", "75"},
+		{"Ada Lovelace", "12 Main St.", "london", "101"},
+		{"Donald Knuth", "5 Hill", "Stanford", "999"},
+	})
+	return arr1, arr2
+}
+
+func TestGetDifferentRowsFuzzy(t *testing.T) {
+	arr1, arr2 := getAddressesCsvArrays()
+	options := csvcheck.Options{Method: csvcheck.MethodFuzzy, SortIndices: true}
+
+	res, err := csvcheck.GetDifferentRowsResult(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 4}, res.Indices1)
+	assert.Equal(t, []int{0, 4}, res.Indices2)
+	assert.Len(t, res.Pairs, 2)
+	paired := make(map[int]int)
+	for i, pair := range res.Pairs {
+		paired[pair.Index1] = pair.Index2
+		assert.GreaterOrEqual(t, pair.Score, csvcheck.DefaultRowMatchThreshold)
+		assert.Less(t, pair.Score, 1.0)
+		if i > 0 {
+			assert.LessOrEqual(t, pair.Score, res.Pairs[i-1].Score)
+		}
+	}
+	assert.Equal(t, map[int]int{1: 3, 3: 2}, paired)
+
+	res, err = csvcheck.GetCommonRowsResult(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, res.Indices1)
+	assert.Equal(t, []int{0, 1, 2, 3}, res.Indices2)
+
+	options.Fuzzy.Threshold = 0.99
+	_, _, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 3, 4}, indices1)
+	assert.Equal(t, []int{0, 2, 3, 4}, indices2)
+}
+
+func TestGetFuzzyRowPairsOptions(t *testing.T) {
+	arr1, arr2 := getAddressesCsvArrays()
+	options := csvcheck.Options{Fuzzy: csvcheck.RowMatchOptions{
+		BlockColumns: csvcheck.GetRowFromRow([]string{"city"}),
+	}}
+
+	pairs, err := csvcheck.GetFuzzyRowPairs(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Len(t, pairs, 2)
+
+	options.Fuzzy = csvcheck.RowMatchOptions{Threshold: 0.9, Weights: map[string]float64{"street": 0, "city": 0, "name": 3}}
+	pairs, err = csvcheck.GetFuzzyRowPairs(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Len(t, pairs, 2)
+	assert.Equal(t, csvcheck.RowPair{Index1: 1, Index2: 3, Score: pairs[0].Score}, pairs[0])
+	assert.InDelta(t, (3+100.0/101)/4, pairs[0].Score, 1e-9)
+
+	options.Fuzzy = csvcheck.RowMatchOptions{BlockColumns: csvcheck.GetRowFromRow([]string{"zip"})}
+	_, err = csvcheck.GetFuzzyRowPairs(arr1, arr2, options)
+	assert.NotNil(t, err)
+
+	options.Fuzzy = csvcheck.RowMatchOptions{Threshold: 2}
+	_, err = csvcheck.GetFuzzyRowPairs(arr1, arr2, options)
+	assert.NotNil(t, err)
+}
+
+func TestGetDifferentIndicesFuzzy(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{{"apple", "1"}, {"banana", "2"}})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{{"bananas", "2"}, {"kiwi", "4"}})
+
+	_, _, err := csvcheck.GetDifferentIndices(arr1, arr2, csvcheck.MethodFuzzy, true)
+	var unsupported *csvcheck.UnsupportedMethodError
+	assert.ErrorAs(t, err, &unsupported)
+
+	_, _, err = csvcheck.GetCommonIndices(arr1, arr2, csvcheck.MethodFuzzy, true)
+	assert.ErrorAs(t, err, &unsupported)
+}
+
+func TestGetFuzzyRowPairsZeros(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{{"name", "amount"}, {"ada", "0"}})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{{"name", "amount"}, {"ada", "0.00"}})
+
+	pairs, err := csvcheck.GetFuzzyRowPairs(arr1, arr2, csvcheck.Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []csvcheck.RowPair{{Index1: 1, Index2: 1, Score: 1}}, pairs)
+}
+
+func TestGetDifferentRowsFuzzyBlocking(t *testing.T) {
+	rows1 := [][]string{{"id", "value"}}
+	rows2 := [][]string{{"id", "value"}}
+	for i := 0; i < 200; i++ {
+		rows1 = append(rows1, []string{fmt.Sprintf("customer %d smith", i), "shared"})
+		rows2 = append(rows2, []string{fmt.Sprintf("customer %d smyth", i), "shared"})
+	}
+	options := csvcheck.Options{Method: csvcheck.MethodFuzzy, Fuzzy: csvcheck.RowMatchOptions{Threshold: 0.5}}
+
+	pairs, err := csvcheck.GetFuzzyRowPairs(csvcheck.Get2DArrayFrom2DArray(rows1), csvcheck.Get2DArrayFrom2DArray(rows2), options)
+
+	assert.Nil(t, err)
+	assert.Len(t, pairs, 200)
+	for _, pair := range pairs {
+		assert.Equal(t, pair.Index1, pair.Index2)
+	}
+}
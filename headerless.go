@@ -0,0 +1,89 @@
+package csvcheck
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// The prefix of the synthetic column names of arrays without a header row.
+const SyntheticColumnPrefix = "col"
+
+// For referring to a column by its zero based position instead of its name, such as
+// in Options.UseColumns and Options.IgnoreColumns.
+type ColumnIndex int
+
+func (c ColumnIndex) StringHash() string {
+	return SyntheticColumnPrefix + strconv.Itoa(int(c)+1)
+}
+
+// Returns a header row of numColumns synthetic column names, col1 to colN.
+func GetSyntheticHeader(numColumns int) []StringHashable {
+	res := make([]StringHashable, numColumns)
+	for i := range res {
+		res[i] = BasicStringHashable(ColumnIndex(i).StringHash())
+	}
+	return res
+}
+
+// Returns a shallow copy of an array without a header row, preceded by a synthetic
+// header row with as many columns as its first row.
+func AddSyntheticHeader(arr [][]StringHashable) ([][]StringHashable, error) {
+	if len(arr) == 0 {
+		return nil, ErrEmptyArray
+	}
+
+	res := make([][]StringHashable, 0, len(arr)+1)
+	res = append(res, GetSyntheticHeader(len(arr[0])))
+	return append(res, arr...), nil
+}
+
+// Returns the columns with each ColumnIndex replaced by the name of the column at
+// that position in the header row.
+func resolveColumnIndices(header, columns []StringHashable) ([]StringHashable, error) {
+	if columns == nil {
+		return nil, nil
+	}
+
+	res := make([]StringHashable, len(columns))
+	for i, column := range columns {
+		res[i] = column
+		index, ok := column.(ColumnIndex)
+		if !ok {
+			continue
+		}
+		if index < 0 || int(index) >= len(header) {
+			return nil, fmt.Errorf("column index %d out of range for %d columns", index, len(header))
+		}
+		res[i] = header[index]
+	}
+	return res, nil
+}
+
+// Returns the options with the columns of UseColumns and IgnoreColumns given by
+// ColumnIndex resolved against the header row.
+func resolveOptionsColumns(header []StringHashable, options Options) (Options, error) {
+	var err error
+	options.UseColumns, err = resolveColumnIndices(header, options.UseColumns)
+	if err != nil {
+		return options, err
+	}
+	options.IgnoreColumns, err = resolveColumnIndices(header, options.IgnoreColumns)
+	return options, err
+}
+
+// Returns the arrays with synthetic header rows added if options.NoHeader is set.
+func addSyntheticHeaders(csvArray1, csvArray2 [][]StringHashable, options Options) ([][]StringHashable, [][]StringHashable, error) {
+	if !options.NoHeader {
+		return csvArray1, csvArray2, nil
+	}
+
+	res1, err := AddSyntheticHeader(csvArray1)
+	if err != nil {
+		return nil, nil, err
+	}
+	res2, err := AddSyntheticHeader(csvArray2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res1, res2, nil
+}
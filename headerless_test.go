@@ -0,0 +1,102 @@
+package csvcheck_test
+
+import (
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddSyntheticHeader(t *testing.T) {
+	arr := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"1", "1", "2"},
+		{"3", "4", "5"},
+	})
+
+	res, err := csvcheck.AddSyntheticHeader(arr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"col1", "col2", "col3"}), res[0])
+	assert.Equal(t, arr, res[1:])
+	assert.Nil(t, csvcheck.CheckForProperCsvArray(res))
+}
+
+func TestGetDifferentRowsNoHeader(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"1", "1", "2"},
+		{"3", "4", "5"},
+		{"6", "7", "8"},
+	})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"6", "7", "8"},
+		{"1", "1", "2"},
+		{"3", "4", "0"},
+	})
+	options := csvcheck.Options{NoHeader: true, SortIndices: true}
+
+	res1, res2, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, indices1)
+	assert.Equal(t, []int{2}, indices2)
+	assert.Equal(t, arr1[1:2], res1)
+	assert.Equal(t, arr2[2:3], res2)
+}
+
+func TestGetCommonRowsNoHeaderIgnoreColumnIndex(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"1", "1", "2"},
+		{"3", "4", "5"},
+	})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"3", "4", "0"},
+		{"1", "1", "9"},
+	})
+	options := csvcheck.Options{
+		NoHeader:      true,
+		IgnoreColumns: []csvcheck.StringHashable{csvcheck.ColumnIndex(2)},
+		SortIndices:   true,
+	}
+
+	_, _, indices1, indices2, err := csvcheck.GetCommonRows(arr1, arr2, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1}, indices1)
+	assert.Equal(t, []int{0, 1}, indices2)
+
+	options.IgnoreColumns = csvcheck.GetRowFromRow([]string{"col3"})
+	_, _, indices1, _, err = csvcheck.GetCommonRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1}, indices1)
+}
+
+func TestGetCommonRowsUseColumnIndexWithHeader(t *testing.T) {
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"x", "b", "y"},
+		{"1", "2", "0"},
+		{"7", "5", "0"},
+	})
+	options := csvcheck.Options{
+		UseColumns:  []csvcheck.StringHashable{csvcheck.ColumnIndex(0), csvcheck.BasicStringHashable("b")},
+		SortIndices: true,
+	}
+
+	_, _, _, _, err := csvcheck.GetCommonRows(getCsvArray1(), arr2, options)
+	assert.NotNil(t, err)
+
+	arr2[0][0] = csvcheck.BasicStringHashable("a")
+	res1, _, indices1, indices2, err := csvcheck.GetCommonRows(getCsvArray1(), arr2, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1}, indices1)
+	assert.Equal(t, []int{0, 1}, indices2)
+	assert.Equal(t, getCsvArray1()[:2], res1)
+}
+
+func TestColumnIndexOutOfRange(t *testing.T) {
+	options := csvcheck.Options{UseColumns: []csvcheck.StringHashable{csvcheck.ColumnIndex(3)}}
+
+	_, _, _, _, err := csvcheck.GetCommonRows(getCsvArray1(), getCsvArray2(), options)
+
+	assert.NotNil(t, err)
+}
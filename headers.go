@@ -0,0 +1,245 @@
+package csvcheck
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Supported kinds of near-miss column names.
+const (
+	SuggestionWhitespace = iota
+	SuggestionCase
+	SuggestionEditDistance
+)
+
+// The largest edit distance between two column names for them to be suggested as a near miss.
+const SuggestionMaxDistance = 2
+
+// For holding a pair of column names that likely refer to the same column.
+type ColumnSuggestion struct {
+	Left     StringHashable
+	Right    StringHashable
+	Kind     int
+	Distance int // The edit distance between the names, ignoring case and surrounding whitespace.
+}
+
+// For holding the result of reconciling the header rows of two csv arrays.
+type HeaderReport struct {
+	OnlyLeft        []StringHashable // Compared columns missing from the right.
+	OnlyRight       []StringHashable // Compared columns missing from the left.
+	DuplicatesLeft  []StringHashable // Compared columns appearing more than once in the left.
+	DuplicatesRight []StringHashable // Compared columns appearing more than once in the right.
+	Suggestions     []ColumnSuggestion
+}
+
+// Checks if the compared columns of both headers match up.
+func (r HeaderReport) Ok() bool {
+	return len(r.OnlyLeft) == 0 && len(r.OnlyRight) == 0 && len(r.DuplicatesLeft) == 0 && len(r.DuplicatesRight) == 0
+}
+
+// Returns a description of the suggestion such as "Name -> name (differs in case)".
+func (s ColumnSuggestion) String() string {
+	var reason string
+	switch s.Kind {
+	case SuggestionWhitespace:
+		reason = "differs in whitespace"
+	case SuggestionCase:
+		reason = "differs in case"
+	default:
+		reason = fmt.Sprintf("edit distance %d", s.Distance)
+	}
+	return fmt.Sprintf("%q -> %q (%s)", s.Left.StringHash(), s.Right.StringHash(), reason)
+}
+
+// Returns a human readable description of the report, one problem per line.
+func (r HeaderReport) String() string {
+	if r.Ok() {
+		return "columns match\n"
+	}
+
+	var sb strings.Builder
+	lines := []struct {
+		title   string
+		columns []StringHashable
+	}{
+		{"columns only in left", r.OnlyLeft},
+		{"columns only in right", r.OnlyRight},
+		{"duplicate columns in left", r.DuplicatesLeft},
+		{"duplicate columns in right", r.DuplicatesRight},
+	}
+	for _, line := range lines {
+		if len(line.columns) > 0 {
+			fmt.Fprintf(&sb, "%s: %s\n", line.title, strings.Join(getStringsRow(line.columns), ", "))
+		}
+	}
+	if len(r.Suggestions) > 0 {
+		sb.WriteString("possible matches:\n")
+		for _, suggestion := range r.Suggestions {
+			fmt.Fprintf(&sb, "  %s\n", suggestion)
+		}
+	}
+	return sb.String()
+}
+
+// Returns the edit distance between two strings, counted in characters.
+func getEditDistance(s1, s2 string) int {
+	r1 := []rune(s1)
+	r2 := []rune(s2)
+	previous := make([]int, len(r2)+1)
+	current := make([]int, len(r2)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(r1); i++ {
+		current[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(r2)]
+}
+
+// Returns how left and right are a near miss of each other, if they are.
+func getColumnSuggestion(left, right StringHashable) (ColumnSuggestion, bool) {
+	s1 := strings.Join(strings.Fields(left.StringHash()), " ")
+	s2 := strings.Join(strings.Fields(right.StringHash()), " ")
+	res := ColumnSuggestion{Left: left, Right: right}
+
+	switch {
+	case s1 == s2:
+		res.Kind = SuggestionWhitespace
+	case strings.EqualFold(s1, s2):
+		res.Kind = SuggestionCase
+	default:
+		res.Kind = SuggestionEditDistance
+		res.Distance = getEditDistance(strings.ToLower(s1), strings.ToLower(s2))
+		shortest := min(utf8.RuneCountInString(s1), utf8.RuneCountInString(s2))
+		if res.Distance > SuggestionMaxDistance || res.Distance >= shortest {
+			return res, false
+		}
+	}
+	return res, true
+}
+
+// Returns the columns of the header that are compared with the given options.
+func getComparedColumns(header []StringHashable, options Options) ([]StringHashable, error) {
+	options, err := resolveOptionsColumns(header, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter map[uint64]bool
+	keep := true
+	if options.UseColumns != nil {
+		filter = make(map[uint64]bool)
+		for _, column := range options.UseColumns {
+			filter[getStringKey(column)] = true
+		}
+	} else if options.IgnoreColumns != nil {
+		filter = make(map[uint64]bool)
+		for _, column := range options.IgnoreColumns {
+			filter[getStringKey(column)] = true
+		}
+		keep = false
+	}
+
+	res := []StringHashable{}
+	for _, column := range header {
+		if filter == nil || filter[getStringKey(column)] == keep {
+			res = append(res, column)
+		}
+	}
+	return res, nil
+}
+
+// Returns the columns appearing more than once in the row, in order of their first appearance.
+func getDuplicateColumns(row []StringHashable) []StringHashable {
+	counts := make(map[uint64]int)
+	res := []StringHashable{}
+	for _, column := range row {
+		key := getStringKey(column)
+		counts[key]++
+		if counts[key] == 2 {
+			res = append(res, column)
+		}
+	}
+	return res
+}
+
+// Returns near-miss pairs between the left-only and right-only columns. Each column
+// is suggested at most once, preferring whitespace over case over edit distance
+// differences, and smaller edit distances.
+func getColumnSuggestions(onlyLeft, onlyRight []StringHashable) []ColumnSuggestion {
+	res := []ColumnSuggestion{}
+	used := make([]bool, len(onlyRight))
+	for _, left := range onlyLeft {
+		best := -1
+		var bestSuggestion ColumnSuggestion
+		for j, right := range onlyRight {
+			if used[j] {
+				continue
+			}
+			suggestion, ok := getColumnSuggestion(left, right)
+			if !ok {
+				continue
+			}
+			if best < 0 || suggestion.Kind < bestSuggestion.Kind ||
+				(suggestion.Kind == bestSuggestion.Kind && suggestion.Distance < bestSuggestion.Distance) {
+				best = j
+				bestSuggestion = suggestion
+			}
+		}
+		if best >= 0 {
+			used[best] = true
+			res = append(res, bestSuggestion)
+		}
+	}
+	return res
+}
+
+// Returns a report of how the header rows of the two arrays differ in the columns
+// compared with the given options: columns only in either one, duplicate columns and
+// suggestions for columns that were likely meant to match. Columns paired by
+// options.ColumnMapping are named by their left names, and derived columns are
+// included. The rows below the headers are not checked.
+func ReconcileHeaders(csvArray1, csvArray2 [][]StringHashable, options Options) (HeaderReport, error) {
+	if len(csvArray1) == 0 || len(csvArray2) == 0 {
+		return HeaderReport{}, ErrEmptyArray
+	}
+	err := options.CheckAttributes()
+	if err != nil {
+		return HeaderReport{}, err
+	}
+
+	csvArray1, csvArray2, err = addSyntheticHeaders(csvArray1[:1], csvArray2[:1], options)
+	if err != nil {
+		return HeaderReport{}, err
+	}
+	csvArray1 = disambiguateColumns(csvArray1, options.DuplicateColumns)
+	csvArray2 = disambiguateColumns(csvArray2, options.DuplicateColumns)
+	csvArray1 = [][]StringHashable{appendDerivedColumnNames(csvArray1[0], options.DerivedColumns1)}
+	csvArray2 = [][]StringHashable{appendDerivedColumnNames(csvArray2[0], options.DerivedColumns2)}
+	header2, _, _ := renameMappedColumns(csvArray2, options.ColumnMapping)
+	columns1, err := getComparedColumns(csvArray1[0], options)
+	if err != nil {
+		return HeaderReport{}, err
+	}
+	columns2, err := getComparedColumns(header2[0], options)
+	if err != nil {
+		return HeaderReport{}, err
+	}
+
+	var res HeaderReport
+	res.OnlyLeft, res.OnlyRight = getColumnsOnlyInEither(columns1, columns2)
+	res.DuplicatesLeft = getDuplicateColumns(columns1)
+	res.DuplicatesRight = getDuplicateColumns(columns2)
+	res.Suggestions = getColumnSuggestions(res.OnlyLeft, res.OnlyRight)
+	return res, nil
+}
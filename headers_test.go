@@ -0,0 +1,88 @@
+package csvcheck_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileHeaders(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "Name", " amount", "adress", "zip", "notes"},
+	})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "name", "amount", "address", "postcode", "notes", "notes"},
+	})
+
+	report, err := csvcheck.ReconcileHeaders(arr1, arr2, csvcheck.Options{})
+
+	assert.Nil(t, err)
+	assert.False(t, report.Ok())
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"Name", " amount", "adress", "zip"}), report.OnlyLeft)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"name", "amount", "address", "postcode"}), report.OnlyRight)
+	assert.Empty(t, report.DuplicatesLeft)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"notes"}), report.DuplicatesRight)
+	assert.Equal(t, []csvcheck.ColumnSuggestion{
+		{Left: csvcheck.BasicStringHashable("Name"), Right: csvcheck.BasicStringHashable("name"), Kind: csvcheck.SuggestionCase},
+		{Left: csvcheck.BasicStringHashable(" amount"), Right: csvcheck.BasicStringHashable("amount"), Kind: csvcheck.SuggestionWhitespace},
+		{Left: csvcheck.BasicStringHashable("adress"), Right: csvcheck.BasicStringHashable("address"), Kind: csvcheck.SuggestionEditDistance, Distance: 1},
+	}, report.Suggestions)
+
+	expected := `columns only in left: Name,  amount, adress, zip
+columns only in right: name, amount, address, postcode
+duplicate columns in right: notes
+possible matches:
+  "Name" -> "name" (differs in case)
+  " amount" -> "amount" (differs in whitespace)
+  "adress" -> "address" (edit distance 1)
+`
+	assert.Equal(t, expected, report.String())
+}
+
+func TestReconcileHeadersFiltered(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{{"a", "b", "c"}})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{{"a", "B", "d"}})
+
+	report, err := csvcheck.ReconcileHeaders(arr1, arr2, csvcheck.Options{UseColumns: csvcheck.GetRowFromRow([]string{"a"})})
+	assert.Nil(t, err)
+	assert.True(t, report.Ok())
+	assert.Equal(t, "columns match\n", report.String())
+
+	report, err = csvcheck.ReconcileHeaders(arr1, arr2, csvcheck.Options{IgnoreColumns: csvcheck.GetRowFromRow([]string{"c", "d"})})
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"b"}), report.OnlyLeft)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"B"}), report.OnlyRight)
+}
+
+func TestReconcileHeadersNoSuggestionForShortNames(t *testing.T) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{{"x"}})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{{"y"}})
+
+	report, err := csvcheck.ReconcileHeaders(arr1, arr2, csvcheck.Options{})
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Suggestions)
+}
+
+func TestReconcileHeadersEmptyArray(t *testing.T) {
+	_, err := csvcheck.ReconcileHeaders(getEmpty2DArray(), getCsvArray1(), csvcheck.Options{})
+
+	assert.True(t, errors.Is(err, csvcheck.ErrEmptyArray))
+}
+
+func TestGetDifferentRowsColumnMismatchSuggestions(t *testing.T) {
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"a", "b", "C"},
+		{"1", "2", "3"},
+	})
+
+	_, _, _, _, err := csvcheck.GetDifferentRows(getCsvArray1(), arr2, csvcheck.Options{})
+
+	var mismatchErr *csvcheck.ColumnMismatchError
+	assert.True(t, errors.As(err, &mismatchErr))
+	assert.Len(t, mismatchErr.Suggestions, 1)
+	assert.Equal(t, `check the columns being compared (only in left: c; only in right: C; possible matches: "c" -> "C" (differs in case))`, err.Error())
+}
@@ -0,0 +1,92 @@
+package csvcheck
+
+import (
+	"context"
+	"iter"
+	"slices"
+)
+
+// Returns an iterator over the rows of arr at the given indices, in ascending order
+// of index, yielding each index with its row. Like KeepRows but without copying the
+// rows. Repeated and out of range indices are skipped.
+func IterRows(arr [][]StringHashable, indices []int) iter.Seq2[int, []StringHashable] {
+	sorted := slices.Clone(indices)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	return func(yield func(int, []StringHashable) bool) {
+		for _, i := range sorted {
+			if i < 0 || i >= len(arr) {
+				continue
+			}
+			if !yield(i, arr[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Returns the rows of arr at the given indices like KeepRows, without checking arr.
+func getRowsAt(arr [][]StringHashable, indices []int) [][]StringHashable {
+	res := [][]StringHashable{}
+	for _, row := range IterRows(arr, indices) {
+		res = append(res, row)
+	}
+	return res
+}
+
+// Returns an iterator yielding the rows of seq, with header in place of the row at index 0.
+func replaceHeader(seq iter.Seq2[int, []StringHashable], header []StringHashable) iter.Seq2[int, []StringHashable] {
+	return func(yield func(int, []StringHashable) bool) {
+		for i, row := range seq {
+			if i == 0 {
+				row = header
+			}
+			if !yield(i, row) {
+				return
+			}
+		}
+	}
+}
+
+// Returns iterators over the rows found, with the columns mapped by options.ColumnMapping
+// named by both their left and right names in the columns rows like GetCommonRows.
+func iterFoundRows(found rowsIndices, options Options) (iter.Seq2[int, []StringHashable], iter.Seq2[int, []StringHashable]) {
+	seq1 := IterRows(found.compared1.rows, found.indices1)
+	seq2 := IterRows(found.compared2.rows, found.indices2)
+	if options.NoHeader {
+		return seq1, seq2
+	}
+
+	_, renamed, _ := renameMappedColumns(found.compared2.rows, options.ColumnMapping)
+	if len(renamed) == 0 {
+		return seq1, seq2
+	}
+	headers := [][]StringHashable{found.compared1.rows[0], found.compared2.rows[0]}
+	setMappedHeaders(headers[:1], headers[1:], renamed)
+	return replaceHeader(seq1, headers[0]), replaceHeader(seq2, headers[1])
+}
+
+// Returns iterators over the common rows between the two arrays based on the given
+// options, along with the indices of the rows in the original arrays. They yield the
+// same rows as GetCommonRows, starting with the columns row unless options.NoHeader is set.
+func IterCommonRows(csvArray1, csvArray2 [][]StringHashable, options Options) (iter.Seq2[int, []StringHashable], iter.Seq2[int, []StringHashable], error) {
+	found, err := getRowsIndices(context.Background(), csvArray1, csvArray2, options, nil, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	seq1, seq2 := iterFoundRows(found, options)
+	return seq1, seq2, nil
+}
+
+// Returns iterators over the different rows between the two arrays based on the given
+// options, along with the indices of the rows in the original arrays. They yield the
+// same rows as GetDifferentRows, starting with the columns row unless options.NoHeader is set.
+func IterDifferentRows(csvArray1, csvArray2 [][]StringHashable, options Options) (iter.Seq2[int, []StringHashable], iter.Seq2[int, []StringHashable], error) {
+	found, err := getRowsIndices(context.Background(), csvArray1, csvArray2, options, nil, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	seq1, seq2 := iterFoundRows(found, options)
+	return seq1, seq2, nil
+}
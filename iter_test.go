@@ -0,0 +1,109 @@
+package csvcheck_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterRows(t *testing.T) {
+	arr := getCsvArray1()
+
+	indices := []int{}
+	rows := [][]csvcheck.StringHashable{}
+	for i, row := range csvcheck.IterRows(arr, []int{3, 0, 3, 7, -1, 1}) {
+		indices = append(indices, i)
+		rows = append(rows, row)
+	}
+
+	expected, _ := csvcheck.KeepRows(arr, []int{0, 1, 3})
+	assert.Equal(t, []int{0, 1, 3}, indices)
+	assert.Equal(t, expected, rows)
+}
+
+func TestIterDifferentRowsMatchesGetDifferentRows(t *testing.T) {
+	arr1 := getCsvArray1()
+	arr2 := getCsvArray2()
+	options := csvcheck.Options{Method: csvcheck.MethodMatch}
+
+	res1, res2, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	seq1, seq2, err := csvcheck.IterDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+
+	collect := func(seq func(func(int, []csvcheck.StringHashable) bool)) ([]int, [][]csvcheck.StringHashable) {
+		indices := []int{}
+		rows := [][]csvcheck.StringHashable{}
+		for i, row := range seq {
+			indices = append(indices, i)
+			rows = append(rows, row)
+		}
+		return indices, rows
+	}
+	iterIndices1, rows1 := collect(seq1)
+	iterIndices2, rows2 := collect(seq2)
+
+	assert.Equal(t, res1, rows1)
+	assert.Equal(t, res2, rows2)
+	assert.ElementsMatch(t, indices1, iterIndices1)
+	assert.ElementsMatch(t, indices2, iterIndices2)
+	assert.Equal(t, []int{0, 1, 3, 4}, iterIndices2)
+}
+
+func TestIterCommonRowsColumnMapping(t *testing.T) {
+	arr1 := getCsvArray1()
+	arr2 := Get2DArrayFromCsvString("x,b,c\n1,2,3\n4,5,6\n")
+	options := csvcheck.Options{Method: csvcheck.MethodMatch, SortIndices: true, ColumnMapping: map[string]string{"a": "x"}}
+
+	res1, res2, _, _, err := csvcheck.GetCommonRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	seq1, seq2, err := csvcheck.IterCommonRows(arr1, arr2, options)
+	assert.Nil(t, err)
+
+	rows1 := [][]csvcheck.StringHashable{}
+	for _, row := range seq1 {
+		rows1 = append(rows1, row)
+	}
+	rows2 := [][]csvcheck.StringHashable{}
+	for _, row := range seq2 {
+		rows2 = append(rows2, row)
+	}
+	assert.Equal(t, res1, rows1)
+	assert.Equal(t, res2, rows2)
+	assert.Equal(t, csvcheck.BasicStringHashable("a / x"), rows1[0][0])
+	assert.Equal(t, csvcheck.BasicStringHashable("a / x"), rows2[0][0])
+}
+
+func TestIterCommonRowsStopEarly(t *testing.T) {
+	seq1, _, err := csvcheck.IterCommonRows(getCsvArray1(), getCsvArray2(), csvcheck.Options{Method: csvcheck.MethodSet})
+	assert.Nil(t, err)
+
+	indices := []int{}
+	for i := range seq1 {
+		if i > 0 {
+			indices = append(indices, i)
+			break
+		}
+	}
+	assert.Equal(t, []int{1}, indices)
+}
+
+func TestIterCommonRowsInvalidOptions(t *testing.T) {
+	_, _, err := csvcheck.IterCommonRows(getCsvArray1(), getCsvArray2(), csvcheck.Options{Method: 100})
+
+	assert.NotNil(t, err)
+}
+
+func TestWriteCsvRows(t *testing.T) {
+	_, seq2, err := csvcheck.IterDifferentRows(getCsvArray1(), getCsvArray2(), csvcheck.Options{Method: csvcheck.MethodMatch})
+	assert.Nil(t, err)
+
+	var sb strings.Builder
+	err = csvcheck.WriteCsvRows(&sb, seq2, csvcheck.DialectCsv)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b,c\n10,10,10\n4,5,6\n4,5,6\n", sb.String())
+}
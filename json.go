@@ -0,0 +1,312 @@
+package csvcheck
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Supported strategies for flattening JSON arrays.
+const (
+	JsonArrayJoin  = iota // Join scalar elements with the array separator. Arrays of non-scalars are kept as JSON.
+	JsonArrayJson         // Keep the whole array as JSON text.
+	JsonArrayIndex        // Flatten every element into its own column, such as "tags.0" and "tags.1".
+)
+
+// The separator used by JsonArrayJoin when none is given.
+const DefaultJsonArraySeparator = ";"
+
+// For holding the options used when reading JSON records.
+type JsonReadOptions struct {
+	ArrayStrategy  int
+	ArraySeparator string // Use an empty string for DefaultJsonArraySeparator.
+	NullValue      string // Rendered in place of null values.
+	MissingValue   string // Rendered for keys missing from a record.
+}
+
+// A JSON object that keeps the order of its keys.
+type jsonObject struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// The union of the flattened keys of JSON records, in order of first appearance.
+type jsonHeader struct {
+	keys  []string
+	known map[string]bool
+}
+
+func (h *jsonHeader) add(key string) {
+	if !h.known[key] {
+		h.known[key] = true
+		h.keys = append(h.keys, key)
+	}
+}
+
+// Checks if the options are valid.
+func (o *JsonReadOptions) CheckAttributes() error {
+	if o.ArrayStrategy != JsonArrayJoin && o.ArrayStrategy != JsonArrayJson && o.ArrayStrategy != JsonArrayIndex {
+		return &InvalidOptionError{Option: "array strategy", Value: fmt.Sprint(o.ArrayStrategy)}
+	}
+	return nil
+}
+
+// Reads the next JSON value from the decoder, keeping the order of object keys.
+func readJsonValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := &jsonObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readJsonValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			object.keys = append(object.keys, key.(string))
+			object.values = append(object.values, value)
+		}
+		_, err = dec.Token()
+		return object, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for dec.More() {
+			value, err := readJsonValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = dec.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// Returns the string rendering of a scalar JSON value.
+func formatJsonScalar(value interface{}, options JsonReadOptions) string {
+	switch v := value.(type) {
+	case nil:
+		return options.NullValue
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// Returns true iff the value is not an object or an array.
+func isJsonScalar(value interface{}) bool {
+	switch value.(type) {
+	case *jsonObject, []interface{}:
+		return false
+	}
+	return true
+}
+
+// Flattens a JSON value into fields named by dotted paths, in order of appearance.
+// Returns an error if two values are flattened into the same field, such as the
+// values of the keys "a.b" and "a" holding {"b": ...}.
+func flattenJsonValue(path string, value interface{}, options JsonReadOptions, header *jsonHeader, fields map[string]string) error {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	set := func(s string) error {
+		if _, exists := fields[path]; exists {
+			return fmt.Errorf("flattened key %s is given more than once", path)
+		}
+		header.add(path)
+		fields[path] = s
+		return nil
+	}
+
+	switch v := value.(type) {
+	case *jsonObject:
+		for i, key := range v.keys {
+			err := flattenJsonValue(join(key), v.values[i], options, header, fields)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		if options.ArrayStrategy == JsonArrayIndex {
+			for i, element := range v {
+				err := flattenJsonValue(join(strconv.Itoa(i)), element, options, header, fields)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if options.ArrayStrategy == JsonArrayJoin {
+			parts := make([]string, len(v))
+			scalars := true
+			for i, element := range v {
+				scalars = scalars && isJsonScalar(element)
+				parts[i] = formatJsonScalar(element, options)
+			}
+			if scalars {
+				separator := options.ArraySeparator
+				if separator == "" {
+					separator = DefaultJsonArraySeparator
+				}
+				return set(strings.Join(parts, separator))
+			}
+		}
+
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return set(string(b))
+	}
+
+	return set(formatJsonScalar(value, options))
+}
+
+// Reads JSON records into a csv array. The input is either a JSON array of objects or
+// a stream of objects such as JSON Lines. The header is the union of the keys of all
+// records in order of first appearance. Nested objects are flattened into dotted paths
+// and arrays according to options.ArrayStrategy. Numbers are kept exactly as written.
+func ReadJson(r io.Reader, options JsonReadOptions) ([][]StringHashable, error) {
+	err := options.CheckAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	isArray := false
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			br.UnreadByte()
+			isArray = b == '['
+			break
+		}
+	}
+
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+	if isArray {
+		_, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	header := &jsonHeader{known: make(map[string]bool)}
+	records := []map[string]string{}
+	for dec.More() {
+		value, err := readJsonValue(dec)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(records), err)
+		}
+		if _, ok := value.(*jsonObject); !ok {
+			return nil, fmt.Errorf("record %d is not an object", len(records))
+		}
+
+		fields := make(map[string]string)
+		err = flattenJsonValue("", value, options, header, fields)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(records), err)
+		}
+		records = append(records, fields)
+	}
+
+	if isArray {
+		_, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([][]StringHashable, len(records)+1)
+	res[0] = GetRowFromRow(header.keys)
+	for i, fields := range records {
+		row := make([]StringHashable, len(header.keys))
+		for j, key := range header.keys {
+			s, exists := fields[key]
+			if !exists {
+				s = options.MissingValue
+			}
+			row[j] = BasicStringHashable(s)
+		}
+		res[i+1] = row
+	}
+	return res, nil
+}
+
+// Reads a JSON or JSON Lines file into a csv array. The file is decompressed and
+// transcoded to UTF-8 as needed, see ReadCsvFile and ReadJson.
+func ReadJsonFile(name string, options JsonReadOptions) ([][]StringHashable, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, _, err := newDecompressingFileReader(f, name, CompressionAuto)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer zr.Close()
+
+	r, _, err := NewUtf8Reader(zr, EncodingAuto)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	res, err := ReadJson(r, options)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,97 @@
+package csvcheck_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadJsonArrayUnionsKeysInOrder(t *testing.T) {
+	s := `[
+  {"id": 1, "name": "ann", "address": {"city": "Oslo", "zip": "0150"}},
+  {"id": 2, "email": null, "address": {"city": "Bergen"}, "score": 1.50}
+]`
+
+	res, err := csvcheck.ReadJson(strings.NewReader(s), csvcheck.JsonReadOptions{NullValue: "null", MissingValue: "-"})
+
+	expected := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "name", "address.city", "address.zip", "email", "score"},
+		{"1", "ann", "Oslo", "0150", "-", "-"},
+		{"2", "-", "Bergen", "-", "null", "1.50"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestReadJsonLinesArrayStrategies(t *testing.T) {
+	s := `{"id": 1, "tags": ["a", "b"], "items": [{"sku": "x", "qty": 2}]}
+{"id": 2, "tags": [], "items": []}
+`
+
+	join, err := csvcheck.ReadJson(strings.NewReader(s), csvcheck.JsonReadOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "tags", "items"},
+		{"1", "a;b", `[{"sku":"x","qty":2}]`},
+		{"2", "", ""},
+	}), join)
+
+	joinPipe, err := csvcheck.ReadJson(strings.NewReader(s), csvcheck.JsonReadOptions{ArraySeparator: "|"})
+	assert.Nil(t, err)
+	assert.Equal(t, "a|b", joinPipe[1][1].StringHash())
+
+	asJson, err := csvcheck.ReadJson(strings.NewReader(s), csvcheck.JsonReadOptions{ArrayStrategy: csvcheck.JsonArrayJson})
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "tags", "items"},
+		{"1", `["a","b"]`, `[{"sku":"x","qty":2}]`},
+		{"2", "[]", "[]"},
+	}), asJson)
+
+	indexed, err := csvcheck.ReadJson(strings.NewReader(s), csvcheck.JsonReadOptions{ArrayStrategy: csvcheck.JsonArrayIndex})
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "tags.0", "tags.1", "items.0.sku", "items.0.qty"},
+		{"1", "a", "b", "x", "2"},
+		{"2", "", "", "", ""},
+	}), indexed)
+}
+
+func TestReadJsonErrors(t *testing.T) {
+	inputs := []string{
+		`[1, 2]`,
+		`{"a": 1} {"a": `,
+		`[{"a": 1}`,
+		`{"a.b": 1, "a": {"b": 2}}`,
+		`{"a": {"b": 2}, "a.b": 1}`,
+	}
+
+	for _, s := range inputs {
+		_, err := csvcheck.ReadJson(strings.NewReader(s), csvcheck.JsonReadOptions{})
+		assert.NotNil(t, err)
+	}
+
+	_, err := csvcheck.ReadJson(strings.NewReader(`[]`), csvcheck.JsonReadOptions{ArrayStrategy: 10})
+	assert.NotNil(t, err)
+}
+
+func TestReadJsonFileAgainstCsv(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "response.jsonl")
+	assert.Nil(t, os.WriteFile(name, []byte(`{"a": "1", "b": 2, "c": 3}
+{"c": 6, "b": 5, "a": 4}
+{"a": 7, "b": 8, "c": 10}
+`), 0o644))
+
+	arr1, err := csvcheck.ReadJsonFile(name, csvcheck.JsonReadOptions{})
+	assert.Nil(t, err)
+
+	_, _, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, getCsvArray1(), csvcheck.Options{SortIndices: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 3}, indices1)
+	assert.Equal(t, []int{0, 3}, indices2)
+}
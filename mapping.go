@@ -0,0 +1,152 @@
+package csvcheck
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Separates the left and right names of mapped columns in the header rows of results.
+const MappedColumnSeparator = " / "
+
+// Checks if no two left columns are mapped to the same right column.
+func checkColumnMapping(mapping map[string]string) error {
+	lefts := make([]string, 0, len(mapping))
+	for left := range mapping {
+		lefts = append(lefts, left)
+	}
+	sort.Strings(lefts)
+
+	seen := make(map[string]string)
+	for _, left := range lefts {
+		right := mapping[left]
+		if other, exists := seen[right]; exists {
+			return &InvalidOptionError{Option: "column mapping", Value: left + "=" + right, Reason: fmt.Sprintf("columns %s and %s are both mapped to %s", other, left, right)}
+		}
+		seen[right] = left
+	}
+	return nil
+}
+
+// Returns the mapping from right column names to left column names.
+func getInverseColumnMapping(mapping map[string]string) map[string]string {
+	res := make(map[string]string)
+	for left, right := range mapping {
+		res[right] = left
+	}
+	return res
+}
+
+// Returns a shallow copy of a right csv array with its mapped columns renamed to their left
+// names, along with the mapping from the new names back to the original ones.
+func renameMappedColumns(arr [][]StringHashable, mapping map[string]string) ([][]StringHashable, map[string]string, error) {
+	renamed := make(map[string]string)
+	if len(mapping) == 0 || len(arr) == 0 {
+		return arr, renamed, nil
+	}
+
+	inverse := getInverseColumnMapping(mapping)
+	header := make([]StringHashable, len(arr[0]))
+	for i, column := range arr[0] {
+		header[i] = column
+		if left, exists := inverse[column.StringHash()]; exists {
+			header[i] = BasicStringHashable(left)
+			renamed[left] = column.StringHash()
+		}
+	}
+
+	res := make([][]StringHashable, len(arr))
+	copy(res, arr)
+	res[0] = header
+	return res, renamed, CheckForProperCsvArray(res[:1])
+}
+
+// Returns the header row with the mapped columns named by both their left and right names,
+// such as "cust_id / CustomerID". names maps the names of the header to their counterparts.
+func getMappedHeader(header []StringHashable, names map[string]string, left bool) []StringHashable {
+	res := make([]StringHashable, len(header))
+	for i, column := range header {
+		res[i] = column
+		other, exists := names[column.StringHash()]
+		if !exists {
+			continue
+		}
+		if left {
+			res[i] = BasicStringHashable(column.StringHash() + MappedColumnSeparator + other)
+		} else {
+			res[i] = BasicStringHashable(other + MappedColumnSeparator + column.StringHash())
+		}
+	}
+	return res
+}
+
+// Replaces the header rows of comparison results with ones naming the mapped columns
+// by both their left and right names. renamed maps the left names of the mapped columns
+// found in the right array to their right names.
+func setMappedHeaders(res1, res2 [][]StringHashable, renamed map[string]string) {
+	if len(renamed) == 0 {
+		return
+	}
+	res1[0] = getMappedHeader(res1[0], renamed, true)
+	res2[0] = getMappedHeader(res2[0], getInverseColumnMapping(renamed), false)
+}
+
+// Returns a csv array of the right side with the columns rearranged like RearrangeColumns.
+// columns holds left names, which are translated to right names using mapping.
+func RearrangeColumnsWithMapping(arr [][]StringHashable, columns []StringHashable, mapping map[string]string) ([][]StringHashable, error) {
+	err := checkColumnMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	rightColumns := make([]StringHashable, len(columns))
+	for i, column := range columns {
+		rightColumns[i] = column
+		if right, exists := mapping[column.StringHash()]; exists {
+			rightColumns[i] = BasicStringHashable(right)
+		}
+	}
+	return RearrangeColumns(arr, rightColumns)
+}
+
+// Aligns the columns of the arrays like AutoAlignCsvArrays, treating the columns of
+// csvArray1 and csvArray2 paired by mapping as common columns. The columns of each
+// result keep their original names.
+func AutoAlignCsvArraysWithMapping(csvArray1, csvArray2 [][]StringHashable, mapping map[string]string) ([][]StringHashable, [][]StringHashable, error) {
+	err := checkColumnMapping(mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+	renamed2, renamed, err := renameMappedColumns(csvArray2, mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res1, res2, err := AutoAlignCsvArrays(csvArray1, renamed2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := make([]StringHashable, len(res2[0]))
+	for i, column := range res2[0] {
+		header[i] = column
+		if right, exists := renamed[column.StringHash()]; exists {
+			header[i] = BasicStringHashable(right)
+		}
+	}
+	res2[0] = header
+	return res1, res2, nil
+}
+
+// Returns the common columns of the arrays like GetCommonColumns, treating the columns
+// paired by mapping as common columns. The columns are named by their left names.
+func GetCommonColumnsWithMapping(csvArray1, csvArray2 [][]StringHashable, mapping map[string]string) ([]StringHashable, error) {
+	err := checkColumnMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+	renamed2, _, err := renameMappedColumns(csvArray2, mapping)
+	if err != nil {
+		return nil, err
+	}
+	return GetCommonColumns(csvArray1, renamed2)
+}
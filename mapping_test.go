@@ -0,0 +1,121 @@
+package csvcheck_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getMappingCsvArrays() ([][]csvcheck.StringHashable, [][]csvcheck.StringHashable) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"cust_id", "name", "amount"},
+		{"1", "x", "10"},
+		{"2", "y", "20"},
+		{"3", "z", "30"},
+	})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"Total", "CustomerID", "name", "extra"},
+		{"20", "2", "y", "a"},
+		{"10", "1", "x", "b"},
+		{"31", "3", "z", "c"},
+	})
+	return arr1, arr2
+}
+
+func TestGetDifferentRowsColumnMapping(t *testing.T) {
+	arr1, arr2 := getMappingCsvArrays()
+	options := csvcheck.Options{
+		IgnoreColumns: csvcheck.GetRowFromRow([]string{"extra"}),
+		ColumnMapping: map[string]string{"cust_id": "CustomerID", "amount": "Total"},
+		SortIndices:   true,
+	}
+
+	res1, res2, indices1, indices2, err := csvcheck.GetDifferentRows(arr1, arr2, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 3}, indices1)
+	assert.Equal(t, []int{0, 3}, indices2)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"cust_id / CustomerID", "name", "amount / Total"},
+		{"3", "z", "30"},
+	}), res1)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"amount / Total", "cust_id / CustomerID", "name", "extra"},
+		{"31", "3", "z", "c"},
+	}), res2)
+	assert.Equal(t, "Total", arr2[0][0].StringHash())
+}
+
+func TestGetCommonRowsColumnMappingUseColumns(t *testing.T) {
+	arr1, arr2 := getMappingCsvArrays()
+	options := csvcheck.Options{
+		UseColumns:    csvcheck.GetRowFromRow([]string{"cust_id", "name"}),
+		ColumnMapping: map[string]string{"cust_id": "CustomerID"},
+		SortIndices:   true,
+	}
+
+	_, _, indices1, indices2, err := csvcheck.GetCommonRows(arr1, arr2, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, indices1)
+	assert.Equal(t, []int{0, 1, 2, 3}, indices2)
+}
+
+func TestColumnMappingInvalid(t *testing.T) {
+	arr1, arr2 := getMappingCsvArrays()
+
+	options := csvcheck.Options{ColumnMapping: map[string]string{"cust_id": "name", "amount": "name"}}
+	assert.NotNil(t, options.CheckAttributes())
+
+	options = csvcheck.Options{ColumnMapping: map[string]string{"name": "CustomerID"}}
+	_, _, _, _, err := csvcheck.GetCommonRows(arr1, arr2, options)
+	var duplicateErr *csvcheck.DuplicateColumnError
+	assert.True(t, errors.As(err, &duplicateErr))
+}
+
+func TestRearrangeColumnsWithMapping(t *testing.T) {
+	arr1, arr2 := getMappingCsvArrays()
+	arr2, _ = csvcheck.IgnoreColumns(arr2, csvcheck.GetRowFromRow([]string{"extra"}))
+
+	res, err := csvcheck.RearrangeColumnsWithMapping(arr2, arr1[0], map[string]string{"cust_id": "CustomerID", "amount": "Total"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"CustomerID", "name", "Total"}), res[0])
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"2", "y", "20"}), res[1])
+}
+
+func TestAutoAlignCsvArraysWithMapping(t *testing.T) {
+	arr1, arr2 := getMappingCsvArrays()
+
+	res1, res2, err := csvcheck.AutoAlignCsvArraysWithMapping(arr1, arr2, map[string]string{"cust_id": "CustomerID", "amount": "Total"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, arr1, res1)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"CustomerID", "name", "Total", "extra"}), res2[0])
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"2", "y", "20", "a"}), res2[1])
+}
+
+func TestGetCommonColumnsWithMapping(t *testing.T) {
+	arr1, arr2 := getMappingCsvArrays()
+
+	res, err := csvcheck.GetCommonColumnsWithMapping(arr1, arr2, map[string]string{"cust_id": "CustomerID"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"cust_id", "name"}), res)
+}
+
+func TestReconcileHeadersColumnMapping(t *testing.T) {
+	arr1, arr2 := getMappingCsvArrays()
+	options := csvcheck.Options{
+		IgnoreColumns: csvcheck.GetRowFromRow([]string{"extra"}),
+		ColumnMapping: map[string]string{"cust_id": "CustomerID", "amount": "Total"},
+	}
+
+	report, err := csvcheck.ReconcileHeaders(arr1, arr2, options)
+
+	assert.Nil(t, err)
+	assert.True(t, report.Ok())
+}
@@ -0,0 +1,312 @@
+package csvcheck
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/types"
+)
+
+// Layouts used for rendering parquet dates, times and timestamps.
+const (
+	ParquetDateLayout      = "2006-01-02"
+	ParquetTimeLayout      = "15:04:05.999999999"
+	ParquetTimestampLayout = time.RFC3339Nano
+)
+
+// For holding the options used when reading parquet files.
+type ParquetReadOptions struct {
+	Columns   []StringHashable // The columns to read, in order. Use nil to read all of them.
+	NullValue string           // Rendered in place of null values.
+}
+
+// A leaf column of a parquet file.
+type parquetColumn struct {
+	index   int64
+	name    string
+	element *parquet.SchemaElement
+}
+
+// Returns a decimal number with the given unscaled value and scale as a string.
+func formatDecimal(unscaled *big.Int, scale int) string {
+	s := new(big.Int).Abs(unscaled).String()
+	if scale > 0 {
+		if len(s) <= scale {
+			s = strings.Repeat("0", scale-len(s)+1) + s
+		}
+		s = s[:len(s)-scale] + "." + s[len(s)-scale:]
+	}
+	if unscaled.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// Returns the signed big-endian two's complement integer in b.
+func getBigIntFromBytes(b []byte) *big.Int {
+	res := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		res.Sub(res, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return res
+}
+
+// Returns the time unit of a timestamp or time column as a duration, or 0 if the
+// column does not hold timestamps or times, respectively.
+func getParquetTimeUnit(element *parquet.SchemaElement, timestamp bool) time.Duration {
+	if logicalType := element.GetLogicalType(); logicalType != nil {
+		var unit *parquet.TimeUnit
+		if timestamp && logicalType.IsSetTIMESTAMP() {
+			unit = logicalType.GetTIMESTAMP().GetUnit()
+		} else if !timestamp && logicalType.IsSetTIME() {
+			unit = logicalType.GetTIME().GetUnit()
+		}
+		switch {
+		case unit == nil:
+		case unit.IsSetMILLIS():
+			return time.Millisecond
+		case unit.IsSetMICROS():
+			return time.Microsecond
+		case unit.IsSetNANOS():
+			return time.Nanosecond
+		}
+	}
+
+	if !element.IsSetConvertedType() {
+		return 0
+	}
+	switch element.GetConvertedType() {
+	case parquet.ConvertedType_TIMESTAMP_MILLIS:
+		if timestamp {
+			return time.Millisecond
+		}
+	case parquet.ConvertedType_TIMESTAMP_MICROS:
+		if timestamp {
+			return time.Microsecond
+		}
+	case parquet.ConvertedType_TIME_MILLIS:
+		if !timestamp {
+			return time.Millisecond
+		}
+	case parquet.ConvertedType_TIME_MICROS:
+		if !timestamp {
+			return time.Microsecond
+		}
+	}
+	return 0
+}
+
+// Returns true iff the column is annotated with the given converted type or
+// the logical type corresponding to it.
+func hasParquetType(element *parquet.SchemaElement, convertedType parquet.ConvertedType) bool {
+	if element.IsSetConvertedType() && element.GetConvertedType() == convertedType {
+		return true
+	}
+
+	logicalType := element.GetLogicalType()
+	if logicalType == nil {
+		return false
+	}
+	switch convertedType {
+	case parquet.ConvertedType_DATE:
+		return logicalType.IsSetDATE()
+	case parquet.ConvertedType_DECIMAL:
+		return logicalType.IsSetDECIMAL()
+	}
+	return false
+}
+
+// Returns the scale of a decimal column.
+func getParquetDecimalScale(element *parquet.SchemaElement) int {
+	if logicalType := element.GetLogicalType(); logicalType != nil && logicalType.IsSetDECIMAL() {
+		return int(logicalType.GetDECIMAL().GetScale())
+	}
+	return int(element.GetScale())
+}
+
+// Returns a deterministic string rendering of a parquet value. Integers and decimals are
+// rendered exactly, floats with the fewest digits that round trip, dates with
+// ParquetDateLayout, times with ParquetTimeLayout and timestamps in UTC with
+// ParquetTimestampLayout. Null values are rendered as nullValue.
+func formatParquetValue(value interface{}, element *parquet.SchemaElement, nullValue string) (string, error) {
+	var integer int64
+	switch v := value.(type) {
+	case nil:
+		return nullValue, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		switch {
+		case element.GetType() == parquet.Type_INT96 && len(v) == 12:
+			return types.INT96ToTime(v).UTC().Format(ParquetTimestampLayout), nil
+		case hasParquetType(element, parquet.ConvertedType_DECIMAL):
+			return formatDecimal(getBigIntFromBytes([]byte(v)), getParquetDecimalScale(element)), nil
+		}
+		return v, nil
+	case int32:
+		integer = int64(v)
+	case int64:
+		integer = v
+	default:
+		return "", fmt.Errorf("unsupported parquet value of type %T", value)
+	}
+
+	switch {
+	case hasParquetType(element, parquet.ConvertedType_DATE):
+		return time.Unix(integer*24*60*60, 0).UTC().Format(ParquetDateLayout), nil
+	case hasParquetType(element, parquet.ConvertedType_DECIMAL):
+		return formatDecimal(big.NewInt(integer), getParquetDecimalScale(element)), nil
+	}
+	if unit := getParquetTimeUnit(element, true); unit != 0 {
+		t := time.Unix(0, integer)
+		switch unit {
+		case time.Millisecond:
+			t = time.UnixMilli(integer)
+		case time.Microsecond:
+			t = time.UnixMicro(integer)
+		}
+		return t.UTC().Format(ParquetTimestampLayout), nil
+	}
+	if unit := getParquetTimeUnit(element, false); unit != 0 {
+		return time.Time{}.Add(time.Duration(integer) * unit).Format(ParquetTimeLayout), nil
+	}
+	return strconv.FormatInt(integer, 10), nil
+}
+
+// Returns the leaf columns of a parquet file to read, in the order requested.
+// Only flat schemas are supported.
+func getParquetColumns(pr *reader.ParquetReader, columns []StringHashable) ([]parquetColumn, error) {
+	sh := pr.SchemaHandler
+	all := make([]parquetColumn, len(sh.ValueColumns))
+	for i, path := range sh.ValueColumns {
+		index := sh.MapIndex[path]
+		element := sh.SchemaElements[index]
+		name := sh.GetExName(int(index))
+		if strings.Count(path, "\x01") != 1 || element.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+			return nil, fmt.Errorf("column %s is nested or repeated, which is not supported", name)
+		}
+		all[i] = parquetColumn{index: int64(i), name: name, element: element}
+	}
+
+	if columns == nil {
+		return all, nil
+	}
+
+	mapping := make(map[string]parquetColumn)
+	for _, column := range all {
+		mapping[column.name] = column
+	}
+	res := make([]parquetColumn, len(columns))
+	for i, column := range columns {
+		c, exists := mapping[column.StringHash()]
+		if !exists {
+			return nil, &ColumnNotFoundError{Name: column.StringHash()}
+		}
+		res[i] = c
+	}
+	return res, nil
+}
+
+// Returns the header row made of the names of the columns.
+func getParquetHeader(columns []parquetColumn) []StringHashable {
+	res := make([]StringHashable, len(columns))
+	for i, column := range columns {
+		res[i] = BasicStringHashable(column.name)
+	}
+	return res
+}
+
+// Reads the rows of the next row group of the columns.
+func readParquetRowGroup(pr *reader.ParquetReader, columns []parquetColumn, numRows int64, nullValue string) ([][]StringHashable, error) {
+	rows := make([][]StringHashable, numRows)
+	for i := range rows {
+		rows[i] = make([]StringHashable, len(columns))
+	}
+
+	for j, column := range columns {
+		values, _, _, err := pr.ReadColumnByIndex(column.index, numRows)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(values)) != numRows {
+			return nil, fmt.Errorf("column %s has %d values in a row group of %d rows", column.name, len(values), numRows)
+		}
+
+		for i, value := range values {
+			s, err := formatParquetValue(value, column.element, nullValue)
+			if err != nil {
+				return nil, fmt.Errorf("row %d column %s: %w", i, column.name, err)
+			}
+			rows[i][j] = BasicStringHashable(s)
+		}
+	}
+	return rows, nil
+}
+
+// Reads a parquet file one row group at a time like ReadParquetFileRowGroups, also
+// returning the header row so that it is known for files without row groups.
+func readParquetFileRowGroups(name string, options ParquetReadOptions, fn func(header []StringHashable, rows [][]StringHashable) error) ([]StringHashable, error) {
+	pf, err := local.NewLocalFileReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer pf.Close()
+
+	pr, err := reader.NewParquetColumnReader(pf, 1)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer pr.ReadStop()
+
+	columns, err := getParquetColumns(pr, options.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	header := getParquetHeader(columns)
+
+	for _, rowGroup := range pr.Footer.GetRowGroups() {
+		rows, err := readParquetRowGroup(pr, columns, rowGroup.GetNumRows(), options.NullValue)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		err = fn(header, rows)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
+
+// Reads a parquet file one row group at a time, so that only a single row group is
+// held in memory. fn is called with the header row and the rows of each row group,
+// in order. Reading stops at the first error returned by fn.
+func ReadParquetFileRowGroups(name string, options ParquetReadOptions, fn func(header []StringHashable, rows [][]StringHashable) error) error {
+	_, err := readParquetFileRowGroups(name, options, fn)
+	return err
+}
+
+// Reads a parquet file into a csv array with a header row made of the column names.
+// Values are rendered deterministically so the result can be compared against csv
+// files, see ParquetDateLayout, ParquetTimeLayout and ParquetTimestampLayout.
+func ReadParquetFile(name string, options ParquetReadOptions) ([][]StringHashable, error) {
+	rows := [][]StringHashable{}
+	header, err := readParquetFileRowGroups(name, options, func(_ []StringHashable, group [][]StringHashable) error {
+		rows = append(rows, group...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([][]StringHashable{header}, rows...), nil
+}
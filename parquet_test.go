@@ -0,0 +1,143 @@
+package csvcheck_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type parquetTestRecord struct {
+	Id      int64   `parquet:"name=id, type=INT64"`
+	Name    *string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Price   int64   `parquet:"name=price, type=INT64, convertedtype=DECIMAL, scale=2, precision=10"`
+	Ratio   float64 `parquet:"name=ratio, type=DOUBLE"`
+	Day     int32   `parquet:"name=day, type=INT32, convertedtype=DATE"`
+	Created int64   `parquet:"name=created, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	Active  bool    `parquet:"name=active, type=BOOLEAN"`
+}
+
+func writeParquetTestFile(t *testing.T, rowGroupSize int64) string {
+	name := filepath.Join(t.TempDir(), "table.parquet")
+	fw, err := local.NewLocalFileWriter(name)
+	assert.Nil(t, err)
+
+	pw, err := writer.NewParquetWriter(fw, new(parquetTestRecord), 1)
+	assert.Nil(t, err)
+	pw.RowGroupSize = rowGroupSize
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	foo := "foo"
+	records := []parquetTestRecord{
+		{Id: 1, Name: &foo, Price: 1050, Ratio: 0.1, Day: 19724, Created: time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC).UnixMilli(), Active: true},
+		{Id: 2, Name: nil, Price: -5, Ratio: 2, Day: 0, Created: 0, Active: false},
+	}
+	for _, record := range records {
+		assert.Nil(t, pw.Write(record))
+		if rowGroupSize == 1 {
+			assert.Nil(t, pw.Flush(true))
+		}
+	}
+	assert.Nil(t, pw.WriteStop())
+	assert.Nil(t, fw.Close())
+	return name
+}
+
+func TestReadParquetFile(t *testing.T) {
+	name := writeParquetTestFile(t, 128*1024*1024)
+
+	res, err := csvcheck.ReadParquetFile(name, csvcheck.ParquetReadOptions{NullValue: "NULL"})
+
+	expected := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "name", "price", "ratio", "day", "created", "active"},
+		{"1", "foo", "10.50", "0.1", "2024-01-02", "2024-01-02T03:04:05.006Z", "true"},
+		{"2", "NULL", "-0.05", "2", "1970-01-01", "1970-01-01T00:00:00Z", "false"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestReadParquetFileFarTimestamp(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "far.parquet")
+	fw, err := local.NewLocalFileWriter(name)
+	assert.Nil(t, err)
+	pw, err := writer.NewParquetWriter(fw, new(parquetTestRecord), 1)
+	assert.Nil(t, err)
+	assert.Nil(t, pw.Write(parquetTestRecord{Created: time.Date(3000, 1, 2, 0, 0, 0, 0, time.UTC).UnixMilli()}))
+	assert.Nil(t, pw.WriteStop())
+	assert.Nil(t, fw.Close())
+
+	res, err := csvcheck.ReadParquetFile(name, csvcheck.ParquetReadOptions{Columns: csvcheck.GetRowFromRow([]string{"created"})})
+
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{{"created"}, {"3000-01-02T00:00:00Z"}}), res)
+}
+
+func TestReadParquetFileColumnProjection(t *testing.T) {
+	name := writeParquetTestFile(t, 128*1024*1024)
+
+	res, err := csvcheck.ReadParquetFile(name, csvcheck.ParquetReadOptions{
+		Columns: csvcheck.GetRowFromRow([]string{"price", "id"}),
+	})
+
+	expected := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"price", "id"},
+		{"10.50", "1"},
+		{"-0.05", "2"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+
+	_, err = csvcheck.ReadParquetFile(name, csvcheck.ParquetReadOptions{
+		Columns: csvcheck.GetRowFromRow([]string{"missing"}),
+	})
+	assert.NotNil(t, err)
+}
+
+func TestReadParquetFileRowGroups(t *testing.T) {
+	name := writeParquetTestFile(t, 1)
+
+	numRowGroups := 0
+	ids := []string{}
+	err := csvcheck.ReadParquetFileRowGroups(name, csvcheck.ParquetReadOptions{
+		Columns: csvcheck.GetRowFromRow([]string{"id"}),
+	}, func(header []csvcheck.StringHashable, rows [][]csvcheck.StringHashable) error {
+		assert.Equal(t, csvcheck.GetRowFromRow([]string{"id"}), header)
+		numRowGroups++
+		for _, row := range rows {
+			ids = append(ids, row[0].StringHash())
+		}
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numRowGroups)
+	assert.Equal(t, []string{"1", "2"}, ids)
+}
+
+func TestReadParquetFileAgainstCsv(t *testing.T) {
+	name := writeParquetTestFile(t, 128*1024*1024)
+	parquetArray, err := csvcheck.ReadParquetFile(name, csvcheck.ParquetReadOptions{})
+	assert.Nil(t, err)
+
+	csvArray := Get2DArrayFromCsvString(`
+id,name,price,day,active
+1,foo,10.50,2024-01-02,true
+2,,-0.05,1970-01-02,false
+`)
+	options := csvcheck.Options{
+		UseColumns:  csvcheck.GetRowFromRow([]string{"id", "name", "price", "day", "active"}),
+		SortIndices: true,
+	}
+	_, _, indices1, indices2, err := csvcheck.GetDifferentRows(parquetArray, csvArray, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2}, indices1)
+	assert.Equal(t, []int{0, 2}, indices2)
+}
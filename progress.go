@@ -0,0 +1,96 @@
+package csvcheck
+
+import (
+	"context"
+	"time"
+)
+
+// Supported phases of a comparison.
+const (
+	ProgressPhaseHashing = iota
+	ProgressPhaseMatching
+	ProgressPhaseDone
+)
+
+// The number of rows hashed between progress reports and checks for cancellation.
+const ProgressInterval = 10000
+
+// For holding the progress of a comparison.
+type Progress struct {
+	Phase      int
+	RowsHashed int           // The number of rows of both arrays hashed so far.
+	TotalRows  int           // The number of rows of both arrays.
+	Elapsed    time.Duration // The time since the comparison started.
+	Remaining  time.Duration // Estimated from the hashing rate so far. Zero once hashing is done.
+}
+
+// For receiving progress reports of a comparison.
+type ProgressFunc func(Progress)
+
+// For reporting progress and checking for cancellation while comparing.
+type progressTracker struct {
+	ctx      context.Context
+	progress ProgressFunc
+	start    time.Time
+	current  Progress
+}
+
+// Returns a tracker for a comparison of totalRows rows and reports that hashing started.
+func newProgressTracker(ctx context.Context, progress ProgressFunc, totalRows int) *progressTracker {
+	t := &progressTracker{
+		ctx:      ctx,
+		progress: progress,
+		start:    time.Now(),
+		current:  Progress{Phase: ProgressPhaseHashing, TotalRows: totalRows},
+	}
+	t.report()
+	return t
+}
+
+// Reports the current progress, if anyone is listening.
+func (t *progressTracker) report() {
+	if t.progress == nil {
+		return
+	}
+
+	t.current.Elapsed = time.Since(t.start)
+	t.current.Remaining = 0
+	if t.current.Phase == ProgressPhaseHashing && t.current.RowsHashed > 0 {
+		left := t.current.TotalRows - t.current.RowsHashed
+		t.current.Remaining = t.current.Elapsed * time.Duration(left) / time.Duration(t.current.RowsHashed)
+	}
+	t.progress(t.current)
+}
+
+// Moves on to the given phase. Returns the error of the context if it is done.
+func (t *progressTracker) setPhase(phase int) error {
+	err := t.ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	t.current.Phase = phase
+	t.report()
+	return nil
+}
+
+// Returns the hash keys of the rows of arr, checking for cancellation and
+// reporting progress every ProgressInterval rows.
+func (t *progressTracker) getRowKeys(arr [][]StringHashable) ([]rowKey, error) {
+	keys := make([]rowKey, len(arr))
+	for i, row := range arr {
+		if t.current.RowsHashed%ProgressInterval == 0 {
+			err := t.ctx.Err()
+			if err != nil {
+				return nil, err
+			}
+			if t.current.RowsHashed > 0 {
+				t.report()
+			}
+		}
+
+		keys[i] = getRowKey(row)
+		t.current.RowsHashed++
+	}
+	return keys, nil
+}
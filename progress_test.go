@@ -0,0 +1,88 @@
+package csvcheck_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCommonRowsContextMatchesGetCommonRows(t *testing.T) {
+	arr1 := getCsvArray1()
+	arr2 := getCsvArray2()
+	options := csvcheck.Options{Method: csvcheck.MethodMatch, SortIndices: true}
+
+	res1, res2, indices1, indices2, err := csvcheck.GetCommonRows(arr1, arr2, options)
+	assert.Nil(t, err)
+	ctxRes1, ctxRes2, ctxIndices1, ctxIndices2, err := csvcheck.GetCommonRowsContext(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, res1, ctxRes1)
+	assert.Equal(t, res2, ctxRes2)
+	assert.Equal(t, indices1, ctxIndices1)
+	assert.Equal(t, indices2, ctxIndices2)
+}
+
+func TestGetDifferentRowsContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, _, _, err := csvcheck.GetDifferentRowsContext(ctx, getCsvArray1(), getCsvArray2(), csvcheck.Options{}, nil)
+
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestGetCommonIndicesContextCancelledWhileHashing(t *testing.T) {
+	arr := generateRandom2DArray(nil, 2, 3*csvcheck.ProgressInterval, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	reports := 0
+	progress := func(p csvcheck.Progress) {
+		reports++
+		if p.RowsHashed >= csvcheck.ProgressInterval {
+			cancel()
+		}
+	}
+	_, _, err := csvcheck.GetCommonIndicesContext(ctx, arr, arr, csvcheck.MethodSet, false, progress)
+
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 2, reports)
+}
+
+func TestGetDifferentIndicesContextProgress(t *testing.T) {
+	arr1 := generateRandom2DArray(nil, 2, csvcheck.ProgressInterval, 10)
+	arr2 := generateRandom2DArray(nil, 2, csvcheck.ProgressInterval+5, 10)
+
+	reports := []csvcheck.Progress{}
+	progress := func(p csvcheck.Progress) {
+		reports = append(reports, p)
+	}
+	_, _, err := csvcheck.GetDifferentIndicesContext(context.Background(), arr1, arr2, csvcheck.MethodMatch, true, progress)
+	assert.Nil(t, err)
+
+	total := len(arr1) + len(arr2)
+	phases := []int{}
+	for _, p := range reports {
+		phases = append(phases, p.Phase)
+		assert.Equal(t, total, p.TotalRows)
+	}
+	assert.Equal(t, []int{
+		csvcheck.ProgressPhaseHashing,
+		csvcheck.ProgressPhaseHashing,
+		csvcheck.ProgressPhaseHashing,
+		csvcheck.ProgressPhaseMatching,
+		csvcheck.ProgressPhaseDone,
+	}, phases)
+	assert.Equal(t, 0, reports[0].RowsHashed)
+	assert.Equal(t, total, reports[len(reports)-1].RowsHashed)
+	assert.Equal(t, csvcheck.ProgressInterval, reports[1].RowsHashed)
+}
+
+func TestGetCommonIndicesContextUnsupportedMethod(t *testing.T) {
+	_, _, err := csvcheck.GetCommonIndicesContext(context.Background(), getCsvArray1(), getCsvArray2(), 100, false, nil)
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,113 @@
+package csvcheck
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported ways of handling rows with a different number of cells than the first row.
+const (
+	RaggedRowsFail = iota
+	RaggedRowsPad
+	RaggedRowsTruncate
+	RaggedRowsPadAndTruncate
+	RaggedRowsSkip
+)
+
+// Returns the way of handling ragged rows with the given name.
+func ParseRaggedRows(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "", "fail":
+		return RaggedRowsFail, nil
+	case "pad":
+		return RaggedRowsPad, nil
+	case "truncate":
+		return RaggedRowsTruncate, nil
+	case "fit":
+		return RaggedRowsPadAndTruncate, nil
+	case "skip":
+		return RaggedRowsSkip, nil
+	}
+	return 0, &InvalidOptionError{Option: "ragged rows mode", Value: s}
+}
+
+// Checks if the way of handling ragged rows is supported.
+func checkRaggedRows(policy int) error {
+	if policy < RaggedRowsFail || policy > RaggedRowsSkip {
+		return &InvalidOptionError{Option: "ragged rows mode", Value: fmt.Sprint(policy)}
+	}
+	return nil
+}
+
+// Returns a copy of a row padded with empty cells to the given length.
+func padRow(row []StringHashable, length int) []StringHashable {
+	res := make([]StringHashable, length)
+	copy(res, row)
+	for j := len(row); j < length; j++ {
+		res[j] = BasicStringHashable("")
+	}
+	return res
+}
+
+// Returns a shallow copy of the array with the rows that have a different number of
+// cells than the first row fixed according to policy, along with their indices. Skipped
+// rows are left as they are. Returns a RaggedRowError for rows that cannot be fixed.
+// The array itself is returned if it has no ragged rows.
+func fixRaggedRows(arr [][]StringHashable, policy int) ([][]StringHashable, []int, error) {
+	if len(arr) == 0 {
+		return nil, nil, ErrEmptyArray
+	}
+
+	length := len(arr[0])
+	res := arr
+	ragged := []int{}
+	for i, row := range arr {
+		if len(row) == length {
+			continue
+		}
+
+		fixed := row
+		switch {
+		case policy == RaggedRowsSkip:
+		case len(row) < length && (policy == RaggedRowsPad || policy == RaggedRowsPadAndTruncate):
+			fixed = padRow(row, length)
+		case len(row) > length && (policy == RaggedRowsTruncate || policy == RaggedRowsPadAndTruncate):
+			fixed = row[:length:length]
+		default:
+			return nil, nil, &RaggedRowError{Row: i, Got: len(row), Want: length}
+		}
+
+		if len(ragged) == 0 {
+			res = make([][]StringHashable, len(arr))
+			copy(res, arr)
+		}
+		res[i] = fixed
+		ragged = append(ragged, i)
+	}
+	return res, ragged, nil
+}
+
+// Returns a copy of the array with the rows that have a different number of cells than
+// the first row padded with empty cells, truncated or removed according to policy, along
+// with the indices of those rows in the original array. Returns a RaggedRowError for the
+// first row that the policy does not fix, such as any ragged row with RaggedRowsFail.
+func FixRaggedRows(arr [][]StringHashable, policy int) ([][]StringHashable, []int, error) {
+	fixed, ragged, err := fixRaggedRows(arr, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	if policy != RaggedRowsSkip {
+		return fixed, ragged, nil
+	}
+
+	res := make([][]StringHashable, 0, len(arr)-len(ragged))
+	j := 0
+	for i, row := range fixed {
+		if j < len(ragged) && ragged[j] == i {
+			j++
+			continue
+		}
+		res = append(res, row)
+	}
+	return res, ragged, nil
+}
@@ -0,0 +1,193 @@
+package csvcheck_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getRaggedCsvArray() [][]csvcheck.StringHashable {
+	return csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"a", "b", "c"},
+		{"1", "2", "3"},
+		{"4", "5"},
+		{"7", "8", "9", "x"},
+		{"10", "11", "12"},
+	})
+}
+
+func TestFixRaggedRows(t *testing.T) {
+	arr := getRaggedCsvArray()
+
+	res, ragged, err := csvcheck.FixRaggedRows(arr, csvcheck.RaggedRowsPadAndTruncate)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2, 3}, ragged)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"4", "5", ""}), res[2])
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"7", "8", "9"}), res[3])
+	assert.Len(t, arr[2], 2)
+	assert.Nil(t, csvcheck.CheckForProperCsvArray(res))
+
+	res, ragged, err = csvcheck.FixRaggedRows(arr, csvcheck.RaggedRowsSkip)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2, 3}, ragged)
+	assert.Equal(t, [][]csvcheck.StringHashable{arr[0], arr[1], arr[4]}, res)
+
+	res, ragged, err = csvcheck.FixRaggedRows(getCsvArray1(), csvcheck.RaggedRowsFail)
+	assert.Nil(t, err)
+	assert.Empty(t, ragged)
+	assert.Len(t, res, 4)
+}
+
+func TestFixRaggedRowsUnfixed(t *testing.T) {
+	arr := getRaggedCsvArray()
+	var raggedErr *csvcheck.RaggedRowError
+
+	_, _, err := csvcheck.FixRaggedRows(arr, csvcheck.RaggedRowsFail)
+	assert.True(t, errors.As(err, &raggedErr))
+	assert.Equal(t, csvcheck.RaggedRowError{Row: 2, Got: 2, Want: 3}, *raggedErr)
+
+	_, _, err = csvcheck.FixRaggedRows(arr, csvcheck.RaggedRowsPad)
+	assert.True(t, errors.As(err, &raggedErr))
+	assert.Equal(t, 3, raggedErr.Row)
+
+	_, _, err = csvcheck.FixRaggedRows(arr, csvcheck.RaggedRowsTruncate)
+	assert.True(t, errors.As(err, &raggedErr))
+	assert.Equal(t, 2, raggedErr.Row)
+
+	_, _, err = csvcheck.FixRaggedRows(getEmpty2DArray(), csvcheck.RaggedRowsPad)
+	assert.ErrorIs(t, err, csvcheck.ErrEmptyArray)
+}
+
+func TestParseRaggedRows(t *testing.T) {
+	names := map[string]int{
+		"":         csvcheck.RaggedRowsFail,
+		"fail":     csvcheck.RaggedRowsFail,
+		"pad":      csvcheck.RaggedRowsPad,
+		"Truncate": csvcheck.RaggedRowsTruncate,
+		"fit":      csvcheck.RaggedRowsPadAndTruncate,
+		"skip":     csvcheck.RaggedRowsSkip,
+	}
+	for name, expected := range names {
+		res, err := csvcheck.ParseRaggedRows(name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, res)
+	}
+
+	_, err := csvcheck.ParseRaggedRows("drop")
+	assert.NotNil(t, err)
+}
+
+func TestGetDifferentRowsResultRaggedRows(t *testing.T) {
+	arr1 := getRaggedCsvArray()
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"a", "b", "c"},
+		{"4", "5", ""},
+		{"10", "11", "12"},
+		{"7", "8", "9"},
+	})
+	options := csvcheck.Options{SortIndices: true, RaggedRows: csvcheck.RaggedRowsPadAndTruncate}
+
+	res, err := csvcheck.GetDifferentRowsResult(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1}, res.Indices1)
+	assert.Equal(t, []int{0}, res.Indices2)
+	assert.Equal(t, []int{2, 3}, res.RaggedRows1)
+	assert.Empty(t, res.RaggedRows2)
+
+	res, err = csvcheck.GetCommonRowsResult(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2, 3, 4}, res.Indices1)
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"4", "5", ""}), res.Rows1[1])
+	assert.Equal(t, csvcheck.GetRowFromRow([]string{"7", "8", "9"}), res.Rows1[2])
+}
+
+func TestGetDifferentRowsSkipRaggedRows(t *testing.T) {
+	arr1 := getRaggedCsvArray()
+	arr2 := getCsvArray1()
+	options := csvcheck.Options{SortIndices: true, RaggedRows: csvcheck.RaggedRowsSkip}
+
+	res, err := csvcheck.GetDifferentRowsResult(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 4}, res.Indices1)
+	assert.Equal(t, []int{0, 2, 3}, res.Indices2)
+	assert.Equal(t, []int{2, 3}, res.RaggedRows1)
+
+	options.NoHeader = true
+	res, err = csvcheck.GetDifferentRowsResult(context.Background(), arr1[1:], arr2[1:], options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{3}, res.Indices1)
+	assert.Equal(t, []int{1, 2}, res.Indices2)
+	assert.Equal(t, []int{1, 2}, res.RaggedRows1)
+
+	seq1, _, err := csvcheck.IterDifferentRows(arr1, arr2, csvcheck.Options{RaggedRows: csvcheck.RaggedRowsPad})
+	assert.Nil(t, seq1)
+	var raggedErr *csvcheck.RaggedRowError
+	assert.True(t, errors.As(err, &raggedErr))
+	assert.Equal(t, 3, raggedErr.Row)
+}
+
+func TestGetDifferentRowsUnsupportedRaggedRows(t *testing.T) {
+	_, _, _, _, err := csvcheck.GetDifferentRows(getCsvArray1(), getCsvArray1(), csvcheck.Options{RaggedRows: -1})
+
+	assert.NotNil(t, err)
+}
+
+func TestReadCsvFileRaggedRows(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "ragged.csv")
+	assert.Nil(t, os.WriteFile(name, []byte("a,b\n1,2\n3\n4,5,6\n"), 0o644))
+
+	arr, _, err := csvcheck.ReadCsvFile(name, csvcheck.ReadOptions{RaggedRows: csvcheck.RaggedRowsPadAndTruncate})
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{{"a", "b"}, {"1", "2"}, {"3", ""}, {"4", "5"}}), arr)
+
+	arr, _, err = csvcheck.ReadCsvFile(name, csvcheck.ReadOptions{RaggedRows: csvcheck.RaggedRowsSkip})
+	assert.Nil(t, err)
+	assert.Len(t, arr, 2)
+
+	_, _, err = csvcheck.ReadCsvFile(name, csvcheck.ReadOptions{RaggedRows: csvcheck.RaggedRowsPad})
+	var raggedErr *csvcheck.RaggedRowError
+	assert.ErrorAs(t, err, &raggedErr)
+
+	arr, _, err = csvcheck.ReadCsvFile(name, csvcheck.ReadOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, arr, 4)
+}
+
+func TestReadCsvFileResultRaggedRows(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "ragged.csv")
+	assert.Nil(t, os.WriteFile(name, []byte("a,b\n1,2\n3\n4,5\n6\n"), 0o644))
+
+	res, err := csvcheck.ReadCsvFileResult(name, csvcheck.ReadOptions{RaggedRows: csvcheck.RaggedRowsSkip})
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{{"a", "b"}, {"1", "2"}, {"4", "5"}}), res.Rows)
+	assert.Equal(t, []int{2, 4}, res.RaggedRows)
+
+	res, err = csvcheck.ReadCsvFileResult(name, csvcheck.ReadOptions{RaggedRows: csvcheck.RaggedRowsPad})
+	assert.Nil(t, err)
+	assert.Equal(t, csvcheck.Get2DArrayFrom2DArray([][]string{{"a", "b"}, {"1", "2"}, {"3", ""}, {"4", "5"}, {"6", ""}}), res.Rows)
+	assert.Equal(t, []int{2, 4}, res.RaggedRows)
+
+	res, err = csvcheck.ReadCsvFileResult(name, csvcheck.ReadOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{}, res.RaggedRows)
+}
+
+func TestGetDifferentRowsValidatesOptionsFirst(t *testing.T) {
+	arr := csvcheck.Get2DArrayFrom2DArray([][]string{{"a", "a"}, {"1", "2"}})
+
+	_, _, _, _, err := csvcheck.GetDifferentRows(arr, arr, csvcheck.Options{DuplicateColumns: 100})
+	var duplicateErr *csvcheck.DuplicateColumnError
+	var optionErr *csvcheck.InvalidOptionError
+	assert.False(t, errors.As(err, &duplicateErr))
+	assert.True(t, errors.As(err, &optionErr))
+
+	_, err = csvcheck.FindDuplicateRows(arr, csvcheck.Options{DuplicateColumns: 100})
+	assert.False(t, errors.As(err, &duplicateErr))
+	assert.True(t, errors.As(err, &optionErr))
+}
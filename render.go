@@ -0,0 +1,265 @@
+package csvcheck
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Box-drawing characters used by RenderTable.
+const (
+	borderHorizontal  = "─"
+	borderVertical    = "│"
+	borderTopLeft     = "┌"
+	borderTopMid      = "┬"
+	borderTopRight    = "┐"
+	borderMidLeft     = "├"
+	borderMidMid      = "┼"
+	borderMidRight    = "┤"
+	borderBottomLeft  = "└"
+	borderBottomMid   = "┴"
+	borderBottomRight = "┘"
+)
+
+// The header of the row numbers column added by RenderTable.
+const RowNumbersHeader = "#"
+
+// For holding the options of the table renderer.
+type TableOptions struct {
+	Borders     bool  // Draw box-drawing borders around every cell.
+	Wrap        bool  // Word-wrap long cells instead of truncating them.
+	MaxWidth    int   // Total width to fit the table into. Use a non-positive value for no limit.
+	MaxColWidth int   // Maximum width of a single column. Use a non-positive value for no limit.
+	RowNumbers  []int // Original indices of the rows, such as those returned by GetCommonRows, shown in a leading column.
+	PageSize    int   // Number of rows below the header per page. Use a non-positive value for a single page.
+}
+
+// Checks if the table options are valid.
+func (o *TableOptions) CheckAttributes(numRows int) error {
+	if o.RowNumbers != nil && len(o.RowNumbers) != numRows {
+		return fmt.Errorf("got %d row numbers for %d rows", len(o.RowNumbers), numRows)
+	}
+	return nil
+}
+
+// Returns the number of characters needed to separate numCols columns.
+func getTableOverhead(numCols int, borders bool) int {
+	if borders {
+		return 3*numCols + 1
+	}
+	return 2 * (numCols - 1)
+}
+
+// Shrinks the widest columns one at a time until the table fits in maxWidth
+// or every column is a single character wide.
+func fitColumnWidths(widths []int, maxWidth int, borders bool) {
+	total := getTableOverhead(len(widths), borders)
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > maxWidth {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 1 {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// Returns the lines of s word-wrapped to the given width. Words longer than
+// the width are broken up.
+func wrapCell(s string, width int) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := []rune{}
+		for _, word := range strings.Fields(paragraph) {
+			runes := []rune(word)
+			for len(runes) > width {
+				if len(line) > 0 {
+					lines = append(lines, string(line))
+					line = []rune{}
+				}
+				lines = append(lines, string(runes[:width]))
+				runes = runes[width:]
+			}
+			if len(runes) == 0 {
+				continue
+			}
+
+			if len(line) == 0 {
+				line = runes
+			} else if len(line)+1+len(runes) <= width {
+				line = append(append(line, ' '), runes...)
+			} else {
+				lines = append(lines, string(line))
+				line = runes
+			}
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// Returns s cut down to the given width, marked with TruncatedMark when
+// there is enough room for it.
+func truncateCell(s string, width int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	markLength := utf8.RuneCountInString(TruncatedMark)
+	if width > markLength {
+		return string(runes[:width-markLength]) + TruncatedMark
+	}
+	return string(runes[:width])
+}
+
+// Returns s padded with spaces on the right up to the given width.
+func padCell(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s)))
+}
+
+// Returns a horizontal border line using the given corner and junction characters.
+func getBorderLine(widths []int, left, mid, right string) string {
+	parts := make([]string, len(widths))
+	for i, w := range widths {
+		parts[i] = strings.Repeat(borderHorizontal, w+2)
+	}
+	return left + strings.Join(parts, mid) + right + "\n"
+}
+
+// Returns the lines of a single (possibly wrapped) table row.
+func renderTableRow(row []string, widths []int, options TableOptions) string {
+	cells := make([][]string, len(row))
+	height := 1
+	for i, s := range row {
+		if options.Wrap {
+			cells[i] = wrapCell(s, widths[i])
+		} else {
+			cells[i] = []string{truncateCell(s, widths[i])}
+		}
+		height = max(height, len(cells[i]))
+	}
+
+	var sb strings.Builder
+	for line := 0; line < height; line++ {
+		parts := make([]string, len(cells))
+		for i, cellLines := range cells {
+			s := ""
+			if line < len(cellLines) {
+				s = cellLines[line]
+			}
+			parts[i] = padCell(s, widths[i])
+		}
+
+		if options.Borders {
+			sb.WriteString(borderVertical + " " + strings.Join(parts, " "+borderVertical+" ") + " " + borderVertical)
+		} else {
+			sb.WriteString(strings.TrimRight(strings.Join(parts, "  "), " "))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Returns a single rendered page made of the header and the given rows.
+func renderTablePage(header []string, rows [][]string, widths []int, options TableOptions) string {
+	var sb strings.Builder
+	if options.Borders {
+		sb.WriteString(getBorderLine(widths, borderTopLeft, borderTopMid, borderTopRight))
+	}
+	sb.WriteString(renderTableRow(header, widths, options))
+	if options.Borders {
+		sb.WriteString(getBorderLine(widths, borderMidLeft, borderMidMid, borderMidRight))
+	} else {
+		parts := make([]string, len(widths))
+		for i, w := range widths {
+			parts[i] = strings.Repeat(borderHorizontal, w)
+		}
+		sb.WriteString(strings.Join(parts, "  ") + "\n")
+	}
+	for _, row := range rows {
+		sb.WriteString(renderTableRow(row, widths, options))
+	}
+	if options.Borders {
+		sb.WriteString(getBorderLine(widths, borderBottomLeft, borderBottomMid, borderBottomRight))
+	}
+	return sb.String()
+}
+
+// Takes a csv array and returns it rendered as a table split into pages.
+// Every page repeats the header row of the csv array, which may contain duplicate names.
+func RenderTablePages(csvArray [][]StringHashable, options TableOptions) ([]string, error) {
+	if len(csvArray) == 0 {
+		return nil, ErrEmptyArray
+	}
+	err := checkRowLengths(csvArray)
+	if err != nil {
+		return nil, err
+	}
+	err = options.CheckAttributes(len(csvArray))
+	if err != nil {
+		return nil, err
+	}
+
+	grid := make([][]string, len(csvArray))
+	for i, row := range csvArray {
+		grid[i] = getStringsRow(row)
+		if options.RowNumbers != nil {
+			number := RowNumbersHeader
+			if i > 0 {
+				number = fmt.Sprintf("%d", options.RowNumbers[i])
+			}
+			grid[i] = append([]string{number}, grid[i]...)
+		}
+	}
+
+	widths := make([]int, len(grid[0]))
+	for _, row := range grid {
+		for j, s := range row {
+			for _, line := range strings.Split(s, "\n") {
+				widths[j] = max(widths[j], utf8.RuneCountInString(line))
+			}
+		}
+	}
+	for j := range widths {
+		if options.MaxColWidth > 0 {
+			widths[j] = min(widths[j], options.MaxColWidth)
+		}
+		widths[j] = max(widths[j], 1)
+	}
+	if options.MaxWidth > 0 {
+		fitColumnWidths(widths, options.MaxWidth, options.Borders)
+	}
+
+	body := grid[1:]
+	pageSize := options.PageSize
+	if pageSize <= 0 || pageSize > len(body) {
+		pageSize = max(len(body), 1)
+	}
+
+	pages := []string{}
+	for start := 0; start == 0 || start < len(body); start += pageSize {
+		end := min(start+pageSize, len(body))
+		pages = append(pages, renderTablePage(grid[0], body[start:end], widths, options))
+	}
+	return pages, nil
+}
+
+// Takes a csv array and returns it rendered as a table. Pages are separated by
+// an empty line.
+func RenderTable(csvArray [][]StringHashable, options TableOptions) (string, error) {
+	pages, err := RenderTablePages(csvArray, options)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(pages, "\n"), nil
+}
@@ -0,0 +1,136 @@
+package csvcheck_test
+
+import (
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTableErrorsOnImproperCsvArray(t *testing.T) {
+	arrs := [][][]csvcheck.StringHashable{
+		getEmpty2DArray(),
+		getImproperCsvArrayDifferingRowLengths(),
+	}
+
+	for _, arr := range arrs {
+		_, err := csvcheck.RenderTable(arr, csvcheck.TableOptions{})
+		assert.NotNil(t, err)
+	}
+}
+
+func TestRenderTableDuplicateColumnNames(t *testing.T) {
+	_, err := csvcheck.RenderTable(getImproperCsvArrayDifferingRepeatedColumnNames(), csvcheck.TableOptions{})
+
+	assert.Nil(t, err)
+}
+
+func TestRenderTableErrorsOnWrongNumberOfRowNumbers(t *testing.T) {
+	arr := getCsvArray1()
+
+	_, err := csvcheck.RenderTable(arr, csvcheck.TableOptions{RowNumbers: []int{0, 1}})
+
+	assert.NotNil(t, err)
+}
+
+func TestRenderTableNoBorders(t *testing.T) {
+	arr := Get2DArrayFromCsvString(`
+aaaa,b,ccc
+1,2,3
+5,88888,7
+`)
+
+	res, err := csvcheck.RenderTable(arr, csvcheck.TableOptions{})
+	expected := `
+aaaa  b      ccc
+────  ─────  ───
+1     2      3
+5     88888  7
+`[1:]
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestRenderTableBordersAndRowNumbers(t *testing.T) {
+	arr := Get2DArrayFromCsvString(`
+a,b
+1,22
+333,4
+`)
+
+	res, err := csvcheck.RenderTable(arr, csvcheck.TableOptions{
+		Borders:    true,
+		RowNumbers: []int{0, 4, 12},
+	})
+	expected := `
+┌────┬─────┬────┐
+│ #  │ a   │ b  │
+├────┼─────┼────┤
+│ 4  │ 1   │ 22 │
+│ 12 │ 333 │ 4  │
+└────┴─────┴────┘
+`[1:]
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestRenderTableWrapFitsMaxWidth(t *testing.T) {
+	arr := Get2DArrayFromCsvString(`
+id,text
+1,the quick brown fox
+`)
+
+	res, err := csvcheck.RenderTable(arr, csvcheck.TableOptions{
+		Borders:  true,
+		Wrap:     true,
+		MaxWidth: 20,
+	})
+	expected := `
+┌────┬─────────────┐
+│ id │ text        │
+├────┼─────────────┤
+│ 1  │ the quick   │
+│    │ brown fox   │
+└────┴─────────────┘
+`[1:]
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestRenderTableTruncatesWithoutWrap(t *testing.T) {
+	arr := Get2DArrayFromCsvString(`
+id,text
+1,abcdefghij
+`)
+
+	res, err := csvcheck.RenderTable(arr, csvcheck.TableOptions{MaxColWidth: 6})
+	expected := `
+id  text
+──  ──────
+1   abcd` + csvcheck.TruncatedMark + `
+`
+	assert.Nil(t, err)
+	assert.Equal(t, expected[1:], res)
+}
+
+func TestRenderTablePages(t *testing.T) {
+	arr := Get2DArrayFromCsvString(`
+a
+1
+2
+3
+`)
+
+	pages, err := csvcheck.RenderTablePages(arr, csvcheck.TableOptions{PageSize: 2})
+	expected := []string{
+		"a\n─\n1\n2\n",
+		"a\n─\n3\n",
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, pages)
+
+	res, err := csvcheck.RenderTable(arr, csvcheck.TableOptions{PageSize: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, "a\n─\n1\n2\n\na\n─\n3\n", res)
+}
@@ -0,0 +1,118 @@
+package csvcheck
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// The layout used for rendering time values of query results by default.
+const SqlTimeLayout = time.RFC3339Nano
+
+// Table names accepted by ReadSqlTable.
+var sqlTableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// For holding the options used when reading query results.
+type SqlOptions struct {
+	NullValue  string // Rendered in place of NULL values.
+	TimeLayout string // The layout of time values. Use an empty string for SqlTimeLayout.
+
+	// Called for every non-NULL value before the default formatting. The value
+	// is formatted by the default rules if ok is false.
+	FormatValue func(value interface{}, columnType *sql.ColumnType) (s string, ok bool)
+}
+
+// Returns the string rendering of a value scanned from a query result.
+func formatSqlValue(value interface{}, columnType *sql.ColumnType, options SqlOptions) string {
+	if value == nil {
+		return options.NullValue
+	}
+	if options.FormatValue != nil {
+		if s, ok := options.FormatValue(value, columnType); ok {
+			return s
+		}
+	}
+
+	switch v := value.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		layout := options.TimeLayout
+		if layout == "" {
+			layout = SqlTimeLayout
+		}
+		return v.Format(layout)
+	}
+	return fmt.Sprint(value)
+}
+
+// Reads all of the rows of a query result into a csv array with a header row made
+// of the column names. The rows are closed afterwards.
+func ReadSqlRows(rows *sql.Rows, options SqlOptions) ([][]StringHashable, error) {
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]StringHashable, len(columnTypes))
+	for i, columnType := range columnTypes {
+		header[i] = BasicStringHashable(columnType.Name())
+	}
+	res := [][]StringHashable{header}
+
+	values := make([]interface{}, len(columnTypes))
+	pointers := make([]interface{}, len(columnTypes))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", len(res), err)
+		}
+
+		row := make([]StringHashable, len(values))
+		for i, value := range values {
+			row[i] = BasicStringHashable(formatSqlValue(value, columnTypes[i], options))
+		}
+		res = append(res, row)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Runs a query through any database/sql driver and reads its result into a csv array.
+// See ReadSqlRows.
+func ReadSqlQuery(db *sql.DB, options SqlOptions, query string, args ...interface{}) ([][]StringHashable, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ReadSqlRows(rows, options)
+}
+
+// Reads a whole table into a csv array. The table name may be qualified with a schema
+// and may only contain letters, digits and underscores since it cannot be passed as a
+// query argument.
+func ReadSqlTable(db *sql.DB, options SqlOptions, table string) ([][]StringHashable, error) {
+	if !sqlTableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name: %s", table)
+	}
+	return ReadSqlQuery(db, options, "SELECT * FROM "+table)
+}
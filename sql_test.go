@@ -0,0 +1,100 @@
+package csvcheck_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func getSqlTestDb(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+CREATE TABLE orders (id INTEGER, customer TEXT, amount REAL, paid BOOLEAN, created TIMESTAMP);
+`)
+	assert.Nil(t, err)
+	_, err = db.Exec(
+		"INSERT INTO orders VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?), (?, ?, ?, ?, ?)",
+		1, "ann", 10.5, true, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		2, nil, 3.0, false, time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC),
+		3, "cid", 0.1, true, nil,
+	)
+	assert.Nil(t, err)
+	return db
+}
+
+func TestReadSqlQuery(t *testing.T) {
+	db := getSqlTestDb(t)
+
+	res, err := csvcheck.ReadSqlQuery(db, csvcheck.SqlOptions{NullValue: "NULL"}, "SELECT id, customer, amount, paid, created FROM orders WHERE id < ? ORDER BY id", 3)
+
+	expected := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "customer", "amount", "paid", "created"},
+		{"1", "ann", "10.5", "true", "2024-01-02T03:04:05Z"},
+		{"2", "NULL", "3", "false", "2024-02-03T04:05:06Z"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestReadSqlQueryFormatOptions(t *testing.T) {
+	db := getSqlTestDb(t)
+
+	res, err := csvcheck.ReadSqlQuery(db, csvcheck.SqlOptions{
+		TimeLayout: "2006-01-02",
+		FormatValue: func(value interface{}, columnType *sql.ColumnType) (string, bool) {
+			if v, ok := value.(float64); ok && columnType.Name() == "amount" {
+				return time.Duration(v * float64(time.Second)).String(), true
+			}
+			return "", false
+		},
+	}, "SELECT amount, created FROM orders ORDER BY id")
+
+	expected := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"amount", "created"},
+		{"10.5s", "2024-01-02"},
+		{"3s", "2024-02-03"},
+		{"100ms", ""},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestReadSqlTable(t *testing.T) {
+	db := getSqlTestDb(t)
+
+	res, err := csvcheck.ReadSqlTable(db, csvcheck.SqlOptions{}, "orders")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(res))
+
+	_, err = csvcheck.ReadSqlTable(db, csvcheck.SqlOptions{}, "orders; DROP TABLE orders")
+	assert.NotNil(t, err)
+	_, err = csvcheck.ReadSqlTable(db, csvcheck.SqlOptions{}, "missing")
+	assert.NotNil(t, err)
+}
+
+func TestReadSqlQueryAgainstCsv(t *testing.T) {
+	db := getSqlTestDb(t)
+	arr1, err := csvcheck.ReadSqlQuery(db, csvcheck.SqlOptions{}, "SELECT customer, id, amount FROM orders")
+	assert.Nil(t, err)
+
+	arr2 := Get2DArrayFromCsvString(`
+id,customer,amount
+1,ann,10.5
+2,,3.5
+3,cid,0.1
+`)
+	_, _, indices1, indices2, err := csvcheck.GetCommonRows(arr1, arr2, csvcheck.Options{SortIndices: true})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 3}, indices1)
+	assert.Equal(t, []int{0, 1, 3}, indices2)
+}
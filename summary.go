@@ -0,0 +1,174 @@
+package csvcheck
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// For holding the number of modified rows in which a column differs.
+type ColumnChanges struct {
+	Column StringHashable
+	Count  int
+}
+
+// For holding statistics of comparing two csv arrays.
+type Summary struct {
+	Rows1           int             // The number of compared rows of the first array, below the header.
+	Rows2           int             // The number of compared rows of the second array, below the header.
+	Common1         int             // The number of rows of the first array found in the second.
+	Common2         int             // The number of rows of the second array found in the first.
+	OnlyLeft        int             // The number of rows of the first array not found in the second.
+	OnlyRight       int             // The number of rows of the second array not found in the first.
+	Duplicates1     int             // The number of compared rows of the first array equal to an earlier one.
+	Duplicates2     int             // The number of compared rows of the second array equal to an earlier one.
+	Modified        int             // The number of pairs of rows holding the same record with changes, paired by MethodFuzzy, Options.KeyColumns or their index with MethodDirect.
+	ColumnChanges   []ColumnChanges // The number of modified rows in which each compared column differs, in column order, leaving out unchanged columns.
+	MatchPercentage float64         // The percentage of the compared rows of both arrays that are common.
+}
+
+// Returns the number of rows equal to an earlier row given the mapping of their keys.
+func getDuplicateCount(rowsMapping map[rowKey][]int) int {
+	res := 0
+	for _, indices := range rowsMapping {
+		res += len(indices) - 1
+	}
+	return res
+}
+
+// Returns the pairs of indices of the compared rows holding the same record with
+// changes: the rows paired by MethodFuzzy, the different rows with equal values in
+// options.KeyColumns, or the different rows at the same index with MethodDirect.
+// Returns no pairs otherwise.
+func getModifiedRowPairs(comparison rowsComparison, arr1, arr2 [][]StringHashable, header []StringHashable, options Options) ([][2]int, error) {
+	pairs := [][2]int{}
+	if options.Method == MethodFuzzy {
+		for _, pair := range comparison.pairs {
+			pairs = append(pairs, [2]int{pair.Index1, pair.Index2})
+		}
+		return pairs, nil
+	}
+
+	different1 := append([]int{}, comparison.different1...)
+	different2 := append([]int{}, comparison.different2...)
+	sort.Ints(different1)
+	sort.Ints(different2)
+
+	if options.KeyColumns == nil {
+		if options.Method != MethodDirect {
+			return pairs, nil
+		}
+
+		unpaired2 := make(map[int]bool)
+		for _, i := range different2 {
+			unpaired2[i] = true
+		}
+		for _, i := range different1 {
+			if unpaired2[i] {
+				pairs = append(pairs, [2]int{i, i})
+			}
+		}
+		return pairs, nil
+	}
+
+	keyColumns, err := resolveColumnIndices(header, options.KeyColumns)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range keyColumns {
+		if !slices.ContainsFunc(header, func(c StringHashable) bool { return c.StringHash() == column.StringHash() }) {
+			return nil, &InvalidOptionError{Option: "key columns", Value: column.StringHash(), Reason: fmt.Sprintf("column %s is not compared", column.StringHash())}
+		}
+	}
+	keyIndices, err := getColumnIndices(header, keyColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	unpaired2 := make(map[rowKey][]int)
+	for _, i := range different2 {
+		key := getKeyOfRow(arr2[i], keyIndices)
+		unpaired2[key] = append(unpaired2[key], i)
+	}
+	for _, i := range different1 {
+		key := getKeyOfRow(arr1[i], keyIndices)
+		if candidates := unpaired2[key]; len(candidates) > 0 {
+			pairs = append(pairs, [2]int{i, candidates[0]})
+			unpaired2[key] = candidates[1:]
+		}
+	}
+	return pairs, nil
+}
+
+// Returns the summary of the comparison of the arrays, which hold the compared columns
+// given by header without including it.
+func getSummary(comparison rowsComparison, arr1, arr2 [][]StringHashable, header []StringHashable, options Options) (Summary, error) {
+	pairs, err := getModifiedRowPairs(comparison, arr1, arr2, header, options)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	res := Summary{
+		Rows1:           len(arr1),
+		Rows2:           len(arr2),
+		Common1:         len(comparison.common1),
+		Common2:         len(comparison.common2),
+		OnlyLeft:        len(comparison.different1),
+		OnlyRight:       len(comparison.different2),
+		Duplicates1:     getDuplicateCount(comparison.mapping1),
+		Duplicates2:     getDuplicateCount(comparison.mapping2),
+		Modified:        len(pairs),
+		ColumnChanges:   []ColumnChanges{},
+		MatchPercentage: 100,
+	}
+	if total := res.Rows1 + res.Rows2; total > 0 {
+		res.MatchPercentage = 100 * float64(res.Common1+res.Common2) / float64(total)
+	}
+
+	counts := make([]int, len(header))
+	for _, pair := range pairs {
+		for j := range header {
+			if arr1[pair[0]][j].StringHash() != arr2[pair[1]][j].StringHash() {
+				counts[j]++
+			}
+		}
+	}
+	for j, count := range counts {
+		if count > 0 {
+			res.ColumnChanges = append(res.ColumnChanges, ColumnChanges{Column: header[j], Count: count})
+		}
+	}
+	return res, nil
+}
+
+// Returns the summary as a compact block of text with a line per statistic.
+func (s Summary) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Rows:       %d left, %d right\n", s.Rows1, s.Rows2)
+	fmt.Fprintf(&sb, "Common:     %d left, %d right\n", s.Common1, s.Common2)
+	fmt.Fprintf(&sb, "Only left:  %d\n", s.OnlyLeft)
+	fmt.Fprintf(&sb, "Only right: %d\n", s.OnlyRight)
+	fmt.Fprintf(&sb, "Duplicates: %d left, %d right\n", s.Duplicates1, s.Duplicates2)
+	fmt.Fprintf(&sb, "Modified:   %d\n", s.Modified)
+	if len(s.ColumnChanges) > 0 {
+		changes := make([]string, len(s.ColumnChanges))
+		for i, change := range s.ColumnChanges {
+			changes[i] = fmt.Sprintf("%s %d", change.Column.StringHash(), change.Count)
+		}
+		fmt.Fprintf(&sb, "Changes:    %s\n", strings.Join(changes, ", "))
+	}
+	fmt.Fprintf(&sb, "Match:      %.1f%%\n", s.MatchPercentage)
+	return sb.String()
+}
+
+// Returns the statistics of comparing the two arrays based on the given options,
+// like the Summary of GetDifferentRowsResult.
+func Summarize(ctx context.Context, csvArray1, csvArray2 [][]StringHashable, options Options, progress ProgressFunc) (Summary, error) {
+	found, err := getRowsIndices(ctx, csvArray1, csvArray2, options, progress, false)
+	if err != nil {
+		return Summary{}, err
+	}
+	return found.getSummary(options)
+}
@@ -0,0 +1,132 @@
+package csvcheck_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getOrdersCsvArrays() ([][]csvcheck.StringHashable, [][]csvcheck.StringHashable) {
+	arr1 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "name", "amount"},
+		{"1", "a", "10"},
+		{"2", "b", "20"},
+		{"3", "c", "30"},
+		{"3", "c", "30"},
+	})
+	arr2 := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "name", "amount"},
+		{"1", "a", "10"},
+		{"2", "b", "25"},
+		{"4", "d", "40"},
+	})
+	return arr1, arr2
+}
+
+func TestSummarize(t *testing.T) {
+	arr1, arr2 := getOrdersCsvArrays()
+	options := csvcheck.Options{Method: csvcheck.MethodMatch, KeyColumns: csvcheck.GetRowFromRow([]string{"id"})}
+
+	summary, err := csvcheck.Summarize(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, summary.Rows1)
+	assert.Equal(t, 3, summary.Rows2)
+	assert.Equal(t, 1, summary.Common1)
+	assert.Equal(t, 1, summary.Common2)
+	assert.Equal(t, 3, summary.OnlyLeft)
+	assert.Equal(t, 2, summary.OnlyRight)
+	assert.Equal(t, 1, summary.Duplicates1)
+	assert.Equal(t, 0, summary.Duplicates2)
+	assert.Equal(t, 1, summary.Modified)
+	assert.Equal(t, []csvcheck.ColumnChanges{{Column: csvcheck.BasicStringHashable("amount"), Count: 1}}, summary.ColumnChanges)
+	assert.InDelta(t, 100*2.0/7.0, summary.MatchPercentage, 1e-9)
+
+	expected := "Rows:       4 left, 3 right\n" +
+		"Common:     1 left, 1 right\n" +
+		"Only left:  3\n" +
+		"Only right: 2\n" +
+		"Duplicates: 1 left, 0 right\n" +
+		"Modified:   1\n" +
+		"Changes:    amount 1\n" +
+		"Match:      28.6%\n"
+	assert.Equal(t, expected, summary.String())
+
+	res, err := csvcheck.GetCommonRowsResult(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, summary, res.Summary)
+
+	options.KeyColumns = nil
+	summary, err = csvcheck.Summarize(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, summary.Modified)
+	assert.Equal(t, []csvcheck.ColumnChanges{}, summary.ColumnChanges)
+}
+
+func TestSummarizeDirect(t *testing.T) {
+	arr1, arr2 := getOrdersCsvArrays()
+	options := csvcheck.Options{Method: csvcheck.MethodDirect}
+
+	summary, err := csvcheck.Summarize(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, summary.OnlyLeft)
+	assert.Equal(t, 2, summary.OnlyRight)
+	assert.Equal(t, 2, summary.Modified)
+	assert.Equal(t, []csvcheck.ColumnChanges{
+		{Column: csvcheck.BasicStringHashable("id"), Count: 1},
+		{Column: csvcheck.BasicStringHashable("name"), Count: 1},
+		{Column: csvcheck.BasicStringHashable("amount"), Count: 2},
+	}, summary.ColumnChanges)
+}
+
+func TestSummarizeFuzzy(t *testing.T) {
+	arr1, arr2 := getAddressesCsvArrays()
+	options := csvcheck.Options{Method: csvcheck.MethodFuzzy}
+
+	summary, err := csvcheck.Summarize(context.Background(), arr1, arr2, options, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, summary.Common1)
+	assert.Equal(t, 1, summary.OnlyLeft)
+	assert.Equal(t, 2, summary.Modified)
+	assert.InDelta(t, 75.0, summary.MatchPercentage, 1e-9)
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	arr := csvcheck.Get2DArrayFrom2DArray([][]string{{"a", "b"}})
+
+	summary, err := csvcheck.Summarize(context.Background(), arr, arr, csvcheck.Options{}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, summary.Rows1)
+	assert.Equal(t, 100.0, summary.MatchPercentage)
+}
+
+func TestSummarizeMissingKeyColumn(t *testing.T) {
+	arr1, arr2 := getOrdersCsvArrays()
+	options := csvcheck.Options{KeyColumns: csvcheck.GetRowFromRow([]string{"code"})}
+
+	_, err := csvcheck.Summarize(context.Background(), arr1, arr2, options, nil)
+	var optionErr *csvcheck.InvalidOptionError
+	assert.ErrorAs(t, err, &optionErr)
+	assert.Equal(t, "code", optionErr.Value)
+
+	_, _, _, _, err = csvcheck.GetDifferentRows(arr1, arr2, options)
+	assert.Nil(t, err)
+}
+
+func TestSummarizeIgnoredKeyColumn(t *testing.T) {
+	arr1, arr2 := getOrdersCsvArrays()
+	options := csvcheck.Options{
+		KeyColumns:    csvcheck.GetRowFromRow([]string{"id"}),
+		IgnoreColumns: csvcheck.GetRowFromRow([]string{"id"}),
+	}
+
+	_, err := csvcheck.GetDifferentRowsResult(context.Background(), arr1, arr2, options, nil)
+	var optionErr *csvcheck.InvalidOptionError
+	assert.ErrorAs(t, err, &optionErr)
+	assert.Equal(t, "invalid key columns: column id is not compared", err.Error())
+
+	_, _, _, _, err = csvcheck.GetCommonRows(arr1, arr2, options)
+	assert.Nil(t, err)
+}
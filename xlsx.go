@@ -0,0 +1,362 @@
+package csvcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Names of the sheets written by WriteDiffXlsx.
+const (
+	XlsxSheetLeftOnly  = "Left only"
+	XlsxSheetRightOnly = "Right only"
+	XlsxSheetCommon    = "Common"
+)
+
+// The fill color of changed cells written by WriteDiffXlsx.
+const XlsxChangedCellColor = "FFEB9C"
+
+// For holding the options used when reading xlsx sheets.
+type XlsxReadOptions struct {
+	Sheet      string // The name of the sheet. Takes precedence over SheetIndex.
+	SheetIndex int    // The zero based index of the sheet, used when Sheet is empty.
+	Range      string // A cell range such as "B2:F100". Use an empty string for the whole sheet.
+	HeaderRow  int    // The number of rows within the range above the header row.
+	Raw        bool   // Use raw cell values instead of applying number and date formats.
+}
+
+// For holding the options used when writing xlsx diffs.
+type XlsxWriteOptions struct {
+	// Left-only and right-only rows with equal values in these columns are
+	// paired up and their differing cells highlighted. With MethodDirect,
+	// rows are paired by index when no key columns are given.
+	KeyColumns []StringHashable
+}
+
+// Returns the zero based column and row bounds of a cell range such as "B2:F100".
+func parseXlsxRange(s string) (int, int, int, int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, 0, 0, 0, fmt.Errorf("invalid cell range: %s", s)
+	}
+
+	col1, row1, err := excelize.CellNameToCoordinates(parts[0])
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	col2, row2, err := excelize.CellNameToCoordinates(parts[1])
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return min(col1, col2) - 1, min(row1, row2) - 1, max(col1, col2), max(row1, row2), nil
+}
+
+// Reads a sheet of an xlsx workbook into a csv array. Cells are formatted the way
+// they are displayed unless options.Raw is set. Empty rows are skipped and short
+// rows are padded with empty cells.
+func ReadXlsx(r io.Reader, options XlsxReadOptions) ([][]StringHashable, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheet := options.Sheet
+	if sheet == "" {
+		sheets := f.GetSheetList()
+		if options.SheetIndex < 0 || options.SheetIndex >= len(sheets) {
+			return nil, fmt.Errorf("sheet index %d out of range, the workbook has %d sheets", options.SheetIndex, len(sheets))
+		}
+		sheet = sheets[options.SheetIndex]
+	}
+
+	rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: options.Raw})
+	if err != nil {
+		return nil, err
+	}
+
+	startCol, startRow, endCol, endRow := 0, 0, -1, len(rows)
+	if options.Range != "" {
+		startCol, startRow, endCol, endRow, err = parseXlsxRange(options.Range)
+		if err != nil {
+			return nil, err
+		}
+	}
+	startRow += options.HeaderRow
+	endRow = min(endRow, len(rows))
+
+	if endCol < 0 {
+		for i := startRow; i < endRow; i++ {
+			endCol = max(endCol, len(rows[i]))
+		}
+	}
+
+	res := [][]StringHashable{}
+	for i := startRow; i < endRow; i++ {
+		if len(rows[i]) == 0 {
+			continue
+		}
+
+		row := make([]StringHashable, endCol-startCol)
+		for j := range row {
+			s := ""
+			if startCol+j < len(rows[i]) {
+				s = rows[i][startCol+j]
+			}
+			row[j] = BasicStringHashable(s)
+		}
+		res = append(res, row)
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("sheet %s has no rows in the given range", sheet)
+	}
+	return res, nil
+}
+
+// Reads a sheet of an xlsx file into a csv array. See ReadXlsx.
+func ReadXlsxFile(name string, options XlsxReadOptions) ([][]StringHashable, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	res, err := ReadXlsx(f, options)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return res, nil
+}
+
+// Returns the values of the key columns of a row as a single hashable key.
+func getKeyOfRow(row []StringHashable, keyIndices []int) rowKey {
+	key := make([]StringHashable, len(keyIndices))
+	for i, index := range keyIndices {
+		key[i] = row[index]
+	}
+	return getRowKey(key)
+}
+
+// Returns the index of each of the columns in header, in the order of columns.
+func getColumnIndices(header, columns []StringHashable) ([]int, error) {
+	mapping := make(map[uint64]int)
+	for i, column := range header {
+		mapping[getStringKey(column)] = i
+	}
+
+	res := make([]int, len(columns))
+	for i, column := range columns {
+		index, exists := mapping[getStringKey(column)]
+		if !exists {
+			return nil, &ColumnNotFoundError{Name: column.StringHash()}
+		}
+		res[i] = index
+	}
+	return res, nil
+}
+
+// Returns the key columns named like the columns of the header of the results, which
+// name the columns mapped by mapping by both their left and right names. The key
+// columns can be given by either of these names.
+func getMappedKeyColumns(header, keyColumns []StringHashable, mapping map[string]string) []StringHashable {
+	if keyColumns == nil || len(mapping) == 0 {
+		return keyColumns
+	}
+
+	names := make(map[string]bool)
+	for _, column := range header {
+		names[column.StringHash()] = true
+	}
+	inverse := getInverseColumnMapping(mapping)
+	res := make([]StringHashable, len(keyColumns))
+	for i, column := range keyColumns {
+		res[i] = column
+		name := column.StringHash()
+		if names[name] {
+			continue
+		}
+		if right, exists := mapping[name]; exists && names[name+MappedColumnSeparator+right] {
+			res[i] = BasicStringHashable(name + MappedColumnSeparator + right)
+		} else if left, exists := inverse[name]; exists && names[left+MappedColumnSeparator+name] {
+			res[i] = BasicStringHashable(left + MappedColumnSeparator + name)
+		}
+	}
+	return res
+}
+
+// Returns pairs of indices of rows in res1 and res2 that describe the same record.
+func getChangedRowPairs(res1, res2 [][]StringHashable, indices1, indices2 []int, method int, keyColumns []StringHashable) ([][2]int, error) {
+	pairs := [][2]int{}
+	if keyColumns == nil {
+		if method != MethodDirect {
+			return pairs, nil
+		}
+
+		position2 := make(map[int]int)
+		for i, index := range indices2[1:] {
+			position2[index] = i + 1
+		}
+		for i, index := range indices1[1:] {
+			if j, exists := position2[index]; exists {
+				pairs = append(pairs, [2]int{i + 1, j})
+			}
+		}
+		return pairs, nil
+	}
+
+	keyIndices1, err := getColumnIndices(res1[0], keyColumns)
+	if err != nil {
+		return nil, err
+	}
+	keyIndices2, err := getColumnIndices(res2[0], keyColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	unpaired2 := make(map[rowKey][]int)
+	for i, row := range res2[1:] {
+		key := getKeyOfRow(row, keyIndices2)
+		unpaired2[key] = append(unpaired2[key], i+1)
+	}
+	for i, row := range res1[1:] {
+		key := getKeyOfRow(row, keyIndices1)
+		if candidates := unpaired2[key]; len(candidates) > 0 {
+			pairs = append(pairs, [2]int{i + 1, candidates[0]})
+			unpaired2[key] = candidates[1:]
+		}
+	}
+	return pairs, nil
+}
+
+// Writes the rows of a csv array to a new sheet, preceded by a column with their original indices.
+func writeXlsxSheet(f *excelize.File, sheet string, csvArray [][]StringHashable, indices []int) error {
+	for i, row := range csvArray {
+		values := make([]interface{}, len(row)+1)
+		if i == 0 {
+			values[0] = RowNumbersHeader
+		} else {
+			values[0] = indices[i]
+		}
+		for j, cell := range row {
+			values[j+1] = cell.StringHash()
+		}
+
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		err := f.SetSheetRow(sheet, cell, &values)
+		if err != nil {
+			return err
+		}
+	}
+	return f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
+
+// Writes the result of comparing two csv arrays as an xlsx workbook with separate
+// sheets for left-only, right-only and common rows. The first column of every sheet
+// holds the indices of the rows in the original arrays. Cells that differ between
+// paired left-only and right-only rows are highlighted, see XlsxWriteOptions. Arrays
+// without a header row are written with a synthetic one and their rows numbered from 1.
+func WriteDiffXlsx(w io.Writer, csvArray1, csvArray2 [][]StringHashable, options Options, xlsxOptions XlsxWriteOptions) error {
+	if options.NoHeader {
+		var err error
+		csvArray1, csvArray2, err = addSyntheticHeaders(csvArray1, csvArray2, options)
+		if err != nil {
+			return err
+		}
+		options.NoHeader = false
+	}
+
+	different, err := getRowsIndices(context.Background(), csvArray1, csvArray2, options, nil, false)
+	if err != nil {
+		return err
+	}
+	common := different.withIndices(true, options)
+	different1, different2 := different.getRows(options)
+	differentIndices1, differentIndices2 := different.indices1, different.indices2
+	common1, _ := common.getRows(options)
+	commonIndices1 := common.indices1
+	keyColumns := getMappedKeyColumns(different1[0], xlsxOptions.KeyColumns, options.ColumnMapping)
+	pairs, err := getChangedRowPairs(different1, different2, differentIndices1, differentIndices2, options.Method, keyColumns)
+	if err != nil {
+		return err
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	err = f.SetSheetName("Sheet1", XlsxSheetLeftOnly)
+	if err != nil {
+		return err
+	}
+	for _, sheet := range []string{XlsxSheetRightOnly, XlsxSheetCommon} {
+		_, err = f.NewSheet(sheet)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeXlsxSheet(f, XlsxSheetLeftOnly, different1, differentIndices1)
+	if err != nil {
+		return err
+	}
+	err = writeXlsxSheet(f, XlsxSheetRightOnly, different2, differentIndices2)
+	if err != nil {
+		return err
+	}
+	err = writeXlsxSheet(f, XlsxSheetCommon, common1, commonIndices1)
+	if err != nil {
+		return err
+	}
+
+	changedStyle, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{XlsxChangedCellColor}},
+	})
+	if err != nil {
+		return err
+	}
+
+	columns2 := make(map[uint64]int)
+	for j, column := range different2[0] {
+		columns2[getStringKey(column)] = j
+	}
+	for _, pair := range pairs {
+		for j1, column := range different1[0] {
+			j2, exists := columns2[getStringKey(column)]
+			if !exists || different1[pair[0]][j1].StringHash() == different2[pair[1]][j2].StringHash() {
+				continue
+			}
+
+			cell1, _ := excelize.CoordinatesToCellName(j1+2, pair[0]+1)
+			cell2, _ := excelize.CoordinatesToCellName(j2+2, pair[1]+1)
+			err = f.SetCellStyle(XlsxSheetLeftOnly, cell1, cell1, changedStyle)
+			if err != nil {
+				return err
+			}
+			err = f.SetCellStyle(XlsxSheetRightOnly, cell2, cell2, changedStyle)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return f.Write(w)
+}
+
+// Writes the result of comparing two csv arrays to an xlsx file. See WriteDiffXlsx.
+func WriteDiffXlsxFile(name string, csvArray1, csvArray2 [][]StringHashable, options Options, xlsxOptions XlsxWriteOptions) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	err = WriteDiffXlsx(f, csvArray1, csvArray2, options, xlsxOptions)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
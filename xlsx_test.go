@@ -0,0 +1,210 @@
+package csvcheck_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BrianWeiHaoMa/csvcheck"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func getXlsxWorkbook(t *testing.T) *bytes.Buffer {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	_, err := f.NewSheet("Data")
+	assert.Nil(t, err)
+	rows := [][]interface{}{
+		{"Report generated for finance"},
+		{},
+		{nil, "id", "amount", "date"},
+		{nil, 1, 1234.5, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{nil, 2, 7, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{nil, 3},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		assert.Nil(t, f.SetSheetRow("Data", cell, &row))
+	}
+
+	amountFormat := "#,##0.00"
+	amountStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &amountFormat})
+	assert.Nil(t, err)
+	assert.Nil(t, f.SetCellStyle("Data", "C4", "C5", amountStyle))
+	dateFormat := "yyyy-mm-dd"
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	assert.Nil(t, err)
+	assert.Nil(t, f.SetCellStyle("Data", "D4", "D5", dateStyle))
+
+	var buf bytes.Buffer
+	assert.Nil(t, f.Write(&buf))
+	return &buf
+}
+
+func TestReadXlsxSheetByNameWithRangeAndHeaderRow(t *testing.T) {
+	buf := getXlsxWorkbook(t)
+
+	res, err := csvcheck.ReadXlsx(buf, csvcheck.XlsxReadOptions{
+		Sheet:     "Data",
+		Range:     "B2:D6",
+		HeaderRow: 1,
+	})
+
+	expected := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "amount", "date"},
+		{"1", "1,234.50", "2024-01-02"},
+		{"2", "7.00", "2024-03-04"},
+		{"3", "", ""},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestReadXlsxSheetByIndexRaw(t *testing.T) {
+	buf := getXlsxWorkbook(t)
+
+	res, err := csvcheck.ReadXlsx(buf, csvcheck.XlsxReadOptions{
+		SheetIndex: 1,
+		Range:      "B3:C5",
+		Raw:        true,
+	})
+
+	expected := csvcheck.Get2DArrayFrom2DArray([][]string{
+		{"id", "amount"},
+		{"1", "1234.5"},
+		{"2", "7"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestReadXlsxErrors(t *testing.T) {
+	_, err := csvcheck.ReadXlsx(getXlsxWorkbook(t), csvcheck.XlsxReadOptions{SheetIndex: 5})
+	assert.NotNil(t, err)
+
+	_, err = csvcheck.ReadXlsx(getXlsxWorkbook(t), csvcheck.XlsxReadOptions{Sheet: "Missing"})
+	assert.NotNil(t, err)
+
+	_, err = csvcheck.ReadXlsx(getXlsxWorkbook(t), csvcheck.XlsxReadOptions{Sheet: "Data", Range: "B3"})
+	assert.NotNil(t, err)
+}
+
+func TestWriteDiffXlsxFile(t *testing.T) {
+	arr1 := Get2DArrayFromCsvString(`
+id,name,amount
+1,foo,10
+2,bar,20
+3,baz,30
+`)
+	arr2 := Get2DArrayFromCsvString(`
+amount,id,name
+10,1,foo
+25,2,bar
+40,4,qux
+`)
+	name := filepath.Join(t.TempDir(), "diff.xlsx")
+
+	err := csvcheck.WriteDiffXlsxFile(name, arr1, arr2, csvcheck.Options{SortIndices: true}, csvcheck.XlsxWriteOptions{
+		KeyColumns: csvcheck.GetRowFromRow([]string{"id"}),
+	})
+	assert.Nil(t, err)
+
+	f, err := excelize.OpenFile(name)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	assert.Equal(t, []string{csvcheck.XlsxSheetLeftOnly, csvcheck.XlsxSheetRightOnly, csvcheck.XlsxSheetCommon}, f.GetSheetList())
+
+	leftOnly, err := f.GetRows(csvcheck.XlsxSheetLeftOnly)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"#", "id", "name", "amount"}, {"2", "2", "bar", "20"}, {"3", "3", "baz", "30"}}, leftOnly)
+	rightOnly, err := f.GetRows(csvcheck.XlsxSheetRightOnly)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"#", "amount", "id", "name"}, {"2", "25", "2", "bar"}, {"3", "40", "4", "qux"}}, rightOnly)
+	common, err := f.GetRows(csvcheck.XlsxSheetCommon)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"#", "id", "name", "amount"}, {"1", "1", "foo", "10"}}, common)
+
+	isHighlighted := func(sheet, cell string) bool {
+		styleID, err := f.GetCellStyle(sheet, cell)
+		assert.Nil(t, err)
+		style, err := f.GetStyle(styleID)
+		assert.Nil(t, err)
+		return len(style.Fill.Color) > 0 && style.Fill.Color[0] == csvcheck.XlsxChangedCellColor
+	}
+	assert.True(t, isHighlighted(csvcheck.XlsxSheetLeftOnly, "D2"))
+	assert.False(t, isHighlighted(csvcheck.XlsxSheetLeftOnly, "C2"))
+	assert.False(t, isHighlighted(csvcheck.XlsxSheetLeftOnly, "D3"))
+	assert.True(t, isHighlighted(csvcheck.XlsxSheetRightOnly, "B2"))
+	assert.False(t, isHighlighted(csvcheck.XlsxSheetRightOnly, "B3"))
+}
+
+func TestWriteDiffXlsxDirectPairsByIndex(t *testing.T) {
+	arr1 := getCsvArray1()
+	arr2 := Get2DArrayFromCsvString(`
+a,b,c
+1,2,3
+4,0,6
+`)
+	var buf bytes.Buffer
+
+	err := csvcheck.WriteDiffXlsx(&buf, arr1, arr2, csvcheck.Options{Method: csvcheck.MethodDirect}, csvcheck.XlsxWriteOptions{})
+	assert.Nil(t, err)
+
+	f, err := excelize.OpenReader(&buf)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	styleID, err := f.GetCellStyle(csvcheck.XlsxSheetRightOnly, "C2")
+	assert.Nil(t, err)
+	assert.NotEqual(t, 0, styleID)
+	styleID, err = f.GetCellStyle(csvcheck.XlsxSheetLeftOnly, "C3")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, styleID)
+}
+
+func TestWriteDiffXlsxMappedKeyColumn(t *testing.T) {
+	arr1 := Get2DArrayFromCsvString(`
+id,name,amount
+1,foo,10
+2,bar,20
+`)
+	arr2 := Get2DArrayFromCsvString(`
+ID,name,amount
+1,foo,10
+2,bar,25
+`)
+	options := csvcheck.Options{SortIndices: true, ColumnMapping: map[string]string{"id": "ID"}}
+
+	for _, key := range []string{"id", "ID"} {
+		var buf bytes.Buffer
+		err := csvcheck.WriteDiffXlsx(&buf, arr1, arr2, options, csvcheck.XlsxWriteOptions{
+			KeyColumns: csvcheck.GetRowFromRow([]string{key}),
+		})
+		assert.Nil(t, err)
+
+		f, err := excelize.OpenReader(&buf)
+		assert.Nil(t, err)
+		leftOnly, err := f.GetRows(csvcheck.XlsxSheetLeftOnly)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"#", "id / ID", "name", "amount"}, leftOnly[0])
+		styleID, err := f.GetCellStyle(csvcheck.XlsxSheetLeftOnly, "D2")
+		assert.Nil(t, err)
+		assert.NotEqual(t, 0, styleID)
+		f.Close()
+	}
+}
+
+func TestWriteDiffXlsxMissingKeyColumn(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := csvcheck.WriteDiffXlsx(&buf, getCsvArray1(), getCsvArray2(), csvcheck.Options{}, csvcheck.XlsxWriteOptions{
+		KeyColumns: csvcheck.GetRowFromRow([]string{"z"}),
+	})
+
+	assert.NotNil(t, err)
+}